@@ -0,0 +1,106 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+	"snakes-ml/levels"
+)
+
+func main() {
+	policyNames := flag.String("policy", "astar,hamilton", "comma-separated policies to evaluate: dqn, dqn-int8, safe-dqn, mcts, astar, hamilton")
+	modelFile := flag.String("model", config.ModelBestName, "model file for dqn and mcts policies")
+	episodes := flag.Int("episodes", 100, "number of episodes")
+	seed := flag.Uint64("seed", 1, "seed of the first episode")
+	levelName := flag.String("level", "", "built-in level name or .lvl file (random field if empty)")
+	arena := flag.Bool("arena", false, "play listed policies against each other on one field")
+	layoutName := flag.String("layout", config.ObstacleLayout, "obstacle layout of random fields: scatter, maze, rooms, walls, mixed")
+	flag.Parse()
+
+	opts := snake.DefaultOptions()
+	opts.Seed = *seed
+	layout, err := snake.ParseLayout(*layoutName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.Layout = layout
+	if *levelName != "" {
+		lvl, err := levels.Find(*levelName)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts.Level = lvl
+	}
+
+	if *arena {
+		evaluateArena(strings.Split(*policyNames, ","), *modelFile, opts, *episodes)
+		return
+	}
+
+	for _, name := range strings.Split(*policyNames, ",") {
+		p, err := newPolicy(strings.TrimSpace(name), *modelFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		res := policy.Evaluate(p, opts, *episodes)
+		fmt.Printf("Policy: %-8s | Episodes: %d | Avg score: %.2f | Max score: %d | Avg steps: %.1f | Avg length: %.1f\n",
+			name, res.Episodes, res.AvgScore, res.MaxScore, res.AvgSteps, res.AvgLength)
+	}
+}
+
+// evaluateArena plays named policies against each other
+func evaluateArena(names []string, modelFile string, opts snake.Options, episodes int) {
+	policies := make([]policy.Policy, len(names))
+	for i, name := range names {
+		p, err := newPolicy(strings.TrimSpace(name), modelFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		policies[i] = p
+	}
+
+	arenaOpts := snake.DefaultArenaOptions()
+	arenaOpts.Seed = opts.Seed
+	arenaOpts.Level = opts.Level
+	arenaOpts.Layout = opts.Layout
+
+	res := policy.EvaluateArena(policies, arenaOpts, episodes)
+	for i, name := range names {
+		fmt.Printf("Snake %d: %-8s | Wins: %d/%d | Avg score: %.2f\n",
+			i+1, strings.TrimSpace(name), res.Wins[i], res.Episodes, res.AvgScore[i])
+	}
+	fmt.Printf("Ties: %d\n", res.Ties)
+}
+
+// newPolicy creates policy by name
+func newPolicy(name, modelFile string) (policy.Policy, error) {
+	switch name {
+	case "dqn", "dqn-int8", "safe-dqn", "mcts":
+		net, err := ai.LoadNetworkFor(modelFile, config.GetStateSize(), config.GetActionSize(), config.ActionMode)
+		if err != nil {
+			return nil, fmt.Errorf("load model: %w", err)
+		}
+		switch name {
+		case "dqn":
+			return policy.NewGreedy(net), nil
+		case "dqn-int8":
+			return policy.NewGreedy(ai.Quantize(net)), nil
+		case "safe-dqn":
+			return policy.NewSafeFilter(policy.NewGreedy(net)), nil
+		}
+		return policy.NewMCTS(net, policy.DefaultMCTSConfig()), nil
+	case "astar":
+		return policy.NewAStar(), nil
+	case "hamilton":
+		return policy.NewHamiltonian(), nil
+	default:
+		return nil, fmt.Errorf("unknown policy %q", name)
+	}
+}
@@ -1,22 +1,49 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
+	"os"
+	"os/signal"
 	"snakes-ml/config"
 	"snakes-ml/internal/game"
+	"syscall"
 
 	"github.com/hajimehoshi/ebiten/v2"
 )
 
 func main() {
+	replayFile := flag.String("replay", "", "open recorded episode file in replay mode")
+	flag.Parse()
+
 	// Window configuration from central config
 	ebiten.SetWindowSize(config.WindowWidth, config.WindowHeight)
 	ebiten.SetWindowTitle(config.WindowTitle)
 	ebiten.SetWindowResizingMode(ebiten.WindowResizingModeEnabled)
+	ebiten.SetWindowClosingHandled(true) // Game saves progress before closing
+	ebiten.SetRunnableOnUnfocused(true)  // Keep training and see stop requests in the background
 
 	// Create and run game
 	g := game.NewGame(config.WindowWidth, config.WindowHeight)
 
+	if *replayFile != "" {
+		if err := g.StartReplay(*replayFile); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Ctrl+C and SIGTERM go through the game loop so progress is saved;
+	// a second one kills the process
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		signal.Stop(sig)
+		fmt.Println("\n🛑 Interrupted, saving progress...")
+		g.Stop()
+	}()
+
 	if err := ebiten.RunGame(g); err != nil {
 		log.Fatal(err)
 	}
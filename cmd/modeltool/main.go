@@ -0,0 +1,274 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/imitation"
+	"snakes-ml/internal/onnx"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+)
+
+const usage = `usage: modeltool <command> [files...]
+
+commands:
+  info     validate models and print their metadata
+  migrate  rewrite models in the current format version
+  convert  [-float64] <in> <out>: convert between JSON and binary,
+           the output format follows the extension of <out>
+  onnx     [-samples n] [-tol x] <in> <out.onnx>: export to ONNX and check
+           the exported graph against the network on random states
+  quantize [-dir d] [-prefix p] [-episodes n] <in>: report how an int8 copy
+           agrees with the float network on recorded episode states`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 3 {
+		log.Fatal(usage)
+	}
+
+	cmd, files := os.Args[1], os.Args[2:]
+	switch cmd {
+	case "convert", "onnx", "quantize":
+		run := map[string]func([]string) error{
+			"convert":  convert,
+			"onnx":     exportONNX,
+			"quantize": quantizeReport,
+		}[cmd]
+		if err := run(files); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var run func(string) error
+	switch cmd {
+	case "info":
+		run = info
+	case "migrate":
+		run = migrate
+	default:
+		log.Fatalf("unknown command %q\n%s", cmd, usage)
+	}
+
+	failed := false
+	for _, file := range files {
+		if err := run(file); err != nil {
+			fmt.Printf("⚠️ %v\n", err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// info prints architecture, format info and metadata of a model
+func info(file string) error {
+	net := &ai.Network{}
+	if err := net.LoadFromFile(file); err != nil {
+		return err
+	}
+
+	inf := net.Info()
+	fmt.Printf("✅ %s\n", file)
+	fmt.Printf("  Layers:     %v (%s hidden, %s output)\n", net.Layers(), inf.Activation, inf.Output)
+	if inf.Encoder != "" {
+		fmt.Printf("  Encoder:    %s\n", inf.Encoder)
+	}
+	if inf.ActionMode != "" {
+		fmt.Printf("  Actions:    %s\n", inf.ActionMode)
+	}
+	if inf.LearningRate != 0 {
+		fmt.Printf("  LR:         %g\n", inf.LearningRate)
+	}
+	if t := inf.Training; t != nil {
+		fmt.Printf("  Training:   episode %d, generation %d, %d steps, epsilon %.3f\n",
+			t.Episodes, t.Generation, t.Steps, t.Epsilon)
+		fmt.Printf("  Params:     gamma %g, batch %d, buffer %d, target update %d, epsilon decay %g\n",
+			t.Gamma, t.BatchSize, t.BufferSize, t.UpdateFreq, t.EpsilonDecay)
+	}
+	if !inf.SavedAt.IsZero() {
+		fmt.Printf("  Saved:      %s\n", inf.SavedAt.Format("2006-01-02 15:04:05 MST"))
+	} else {
+		fmt.Println("  Saved:      unknown (version 1 file, run migrate)")
+	}
+
+	meta := net.AllMetadata()
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %-11s %s\n", k+":", meta[k])
+	}
+	return nil
+}
+
+// migrate loads a model of any supported version and saves it back in
+// the current one. Learning rate of version 1 files is unknown and left out.
+func migrate(file string) error {
+	net := &ai.Network{}
+	if err := net.LoadFromFile(file); err != nil {
+		return err
+	}
+	if err := net.SaveToFile(file); err != nil {
+		return fmt.Errorf("save %s: %w", file, err)
+	}
+	fmt.Printf("💾 Migrated %s to format version %d\n", file, ai.ModelVersion)
+	return nil
+}
+
+// convert rewrites a model in the format chosen by the output extension
+func convert(args []string) error {
+	fset := flag.NewFlagSet("convert", flag.ExitOnError)
+	float64s := fset.Bool("float64", config.ModelBinaryFloat64, "store binary parameters as float64 instead of float32")
+	fset.Parse(args)
+	if fset.NArg() != 2 {
+		return fmt.Errorf("convert needs input and output file\n%s", usage)
+	}
+	in, out := fset.Arg(0), fset.Arg(1)
+
+	net := &ai.Network{}
+	if err := net.LoadFromFile(in); err != nil {
+		return err
+	}
+
+	var err error
+	if ai.IsBinaryModel(out) {
+		err = net.SaveBinary(out, *float64s)
+	} else {
+		err = net.SaveToFile(out)
+	}
+	if err != nil {
+		return fmt.Errorf("save %s: %w", out, err)
+	}
+
+	before, _ := os.Stat(in)
+	after, err := os.Stat(out)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("💾 Converted %s (%d KB) -> %s (%d KB)\n", in, before.Size()/1024, out, after.Size()/1024)
+	return nil
+}
+
+// exportONNX writes a model as ONNX, reads the file back and runs it with
+// the interpreter on random states, failing if outputs drift beyond tol
+func exportONNX(args []string) error {
+	fset := flag.NewFlagSet("onnx", flag.ExitOnError)
+	samples := fset.Int("samples", 256, "random states to verify the export on, 0 to skip")
+	tol := fset.Float64("tol", 1e-3, "largest accepted Q-value difference")
+	fset.Parse(args)
+	if fset.NArg() != 2 {
+		return fmt.Errorf("onnx needs input and output file\n%s", usage)
+	}
+	in, out := fset.Arg(0), fset.Arg(1)
+
+	net := &ai.Network{}
+	if err := net.LoadFromFile(in); err != nil {
+		return err
+	}
+	if err := onnx.ExportFile(net, out); err != nil {
+		return fmt.Errorf("export %s: %w", out, err)
+	}
+	fmt.Printf("💾 Exported %s -> %s (layers %v)\n", in, out, net.Layers())
+
+	if *samples <= 0 {
+		return nil
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		return err
+	}
+	res, err := onnx.Verify(net, data, *samples, 1)
+	if err != nil {
+		return fmt.Errorf("verify %s: %w", out, err)
+	}
+	fmt.Printf("Verified on %d states | Max Q error: %.2e | Action mismatches: %d\n",
+		res.Samples, res.MaxAbsError, res.ActionMismatches)
+	if res.MaxAbsError > *tol {
+		return fmt.Errorf("exported graph differs from network by %.2e, more than %.2e", res.MaxAbsError, *tol)
+	}
+	return nil
+}
+
+// quantizeReport compares greedy actions and Q-values of a model and its
+// int8 copy on states from recorded episodes. Without recordings the
+// states come from episodes played by the float network.
+func quantizeReport(args []string) error {
+	fset := flag.NewFlagSet("quantize", flag.ExitOnError)
+	dir := fset.String("dir", config.EpisodeDir, "directory with recorded episodes")
+	prefixes := fset.String("prefix", "", "comma-separated episode file prefixes, empty for all")
+	episodes := fset.Int("episodes", 20, "episodes played for states when there are no recordings")
+	fset.Parse(args)
+	if fset.NArg() != 1 {
+		return fmt.Errorf("quantize needs a model file\n%s", usage)
+	}
+	in := fset.Arg(0)
+
+	net := &ai.Network{}
+	if err := net.LoadFromFile(in); err != nil {
+		return err
+	}
+	q := ai.Quantize(net)
+
+	var filter []string
+	if *prefixes != "" {
+		filter = strings.Split(*prefixes, ",")
+	}
+	exps, recorded, err := imitation.LoadDir(*dir, filter)
+	if err != nil {
+		return err
+	}
+	states := make([][]float64, 0, len(exps))
+	for _, e := range exps {
+		states = append(states, e.State)
+	}
+	source := fmt.Sprintf("%d recorded episodes in %s", recorded, *dir)
+	if len(states) == 0 {
+		states = playStates(net, *episodes)
+		source = fmt.Sprintf("%d episodes of the float greedy policy", *episodes)
+	}
+	if len(states) > 0 && len(states[0]) != net.Layers()[0] {
+		return fmt.Errorf("states have %d features, model expects %d", len(states[0]), net.Layers()[0])
+	}
+
+	r := ai.CompareQuantized(net, q, states)
+	floatSize := 0
+	layers := net.Layers()
+	for i := 0; i+1 < len(layers); i++ {
+		floatSize += 4 * (layers[i] + 1) * layers[i+1]
+	}
+	fmt.Printf("📉 %s: int8 copy on %d states from %s\n", in, r.States, source)
+	fmt.Printf("  Greedy agreement: %.2f%% (%d/%d)\n", 100*r.AgreementRate(), r.Agreement, r.States)
+	fmt.Printf("  Q error:          max %.4f, mean %.4f (max |Q| %.2f)\n", r.MaxAbsError, r.MeanAbsError, r.MaxQ)
+	fmt.Printf("  Parameters:       %d KB int8 vs %d KB float32\n", q.SizeBytes()/1024, floatSize/1024)
+	return nil
+}
+
+// playStates collects states visited by the greedy float policy
+func playStates(net *ai.Network, episodes int) [][]float64 {
+	p := policy.NewGreedy(net)
+	opts := snake.DefaultOptions()
+	var states [][]float64
+	for i := 0; i < episodes; i++ {
+		opts.Seed = uint64(i + 1)
+		s := snake.NewSnakeWithOptions(opts)
+		for {
+			states = append(states, s.GetState())
+			if _, done := s.Step(p.SelectAction(s)); done {
+				break
+			}
+		}
+	}
+	return states
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/imitation"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+)
+
+func main() {
+	record := flag.String("record", "", "comma-separated scripted policies to record demos from first: astar, hamilton")
+	recordEpisodes := flag.Int("record-episodes", 50, "episodes recorded per scripted policy")
+	dir := flag.String("dir", config.EpisodeDir, "directory with recorded episodes")
+	prefixes := flag.String("prefix", config.DemoPrefixes, "comma-separated episode file prefixes used as demos, empty for all")
+	epochs := flag.Int("epochs", config.PretrainEpochs, "passes over the demonstrations")
+	modelFile := flag.String("model", config.ModelBestName, "model to start from, new network if it does not exist")
+	outFile := flag.String("out", config.ModelBestName, "file the pretrained model is saved to")
+	evalEpisodes := flag.Int("eval", 20, "episodes to evaluate the pretrained greedy policy, 0 to skip")
+	seed := flag.Uint64("seed", 1, "seed of the first recorded and evaluated episode")
+	flag.Parse()
+
+	opts := snake.DefaultOptions()
+	opts.Seed = *seed
+
+	if *record != "" {
+		for _, name := range strings.Split(*record, ",") {
+			name = strings.TrimSpace(name)
+			p, err := newScripted(name)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if err := imitation.Record(p, name, opts, *recordEpisodes, *dir); err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("🎬 Recorded %d %s demos to %s\n", *recordEpisodes, name, *dir)
+		}
+	}
+
+	var filter []string
+	if *prefixes != "" {
+		filter = strings.Split(*prefixes, ",")
+	}
+	demos, episodes, err := imitation.LoadDir(*dir, filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(demos) == 0 {
+		log.Fatalf("no demonstrations found in %s (prefixes %q)", *dir, *prefixes)
+	}
+	fmt.Printf("✅ Loaded %d transitions from %d episodes\n", len(demos), episodes)
+
+	agent := ai.NewAgent(config.GetStateSize(), config.GetActionSize(), ai.DefaultConfig())
+	if err := agent.LoadModel(*modelFile); err == nil {
+		fmt.Printf("✅ Starting from %s\n", *modelFile)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Fatal(err)
+	}
+
+	ai.Pretrain(agent.Network(), demos, *epochs, config.Gamma, config.DemoMargin)
+
+	if err := agent.SaveModel(*outFile); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("💾 Pretrained model saved: %s\n", *outFile)
+
+	if *evalEpisodes > 0 {
+		res := policy.Evaluate(policy.NewGreedy(agent.Network()), opts, *evalEpisodes)
+		fmt.Printf("Greedy policy | Episodes: %d | Avg score: %.2f | Max score: %d | Avg steps: %.1f\n",
+			res.Episodes, res.AvgScore, res.MaxScore, res.AvgSteps)
+	}
+}
+
+// newScripted creates scripted demonstrator by name
+func newScripted(name string) (policy.Policy, error) {
+	switch name {
+	case "astar":
+		return policy.NewAStar(), nil
+	case "hamilton":
+		return policy.NewHamiltonian(), nil
+	default:
+		return nil, fmt.Errorf("unknown scripted policy %q", name)
+	}
+}
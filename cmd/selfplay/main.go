@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/league"
+	"snakes-ml/internal/snake"
+)
+
+func main() {
+	episodes := flag.Int("episodes", config.MaxEpisodes, "total self-play rounds; a resumed run continues up to it")
+	modelFile := flag.String("model", config.SelfPlayModelName, "learner model file, loaded if it exists and there is no checkpoint")
+	leagueFile := flag.String("league", config.LeagueFile, "league index file, loaded if it exists")
+	checkpointDir := flag.String("checkpoint", config.SelfPlayCheckpointDir, "training checkpoint directory, resumed if it exists")
+	snakes := flag.Int("snakes", config.SelfPlaySnakes, "snakes per round, learner included")
+	seed := flag.Uint64("seed", 1, "seed of opponent sampling and fields")
+	flag.Parse()
+
+	agent := ai.NewAgent(config.GetStateSize(), config.GetActionSize(), ai.DefaultConfig())
+	if _, err := os.Stat(filepath.Join(*checkpointDir, ai.CheckpointAgentFile)); err == nil {
+		if err := agent.LoadCheckpoint(*checkpointDir, nil); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("♻️ Resumed from %s: episode %d, epsilon %.3f\n", *checkpointDir, agent.EpisodeCount(), agent.Epsilon())
+	} else if err := agent.LoadModel(*modelFile); err == nil {
+		fmt.Printf("✅ Loaded learner from %s\n", *modelFile)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		log.Fatal(err)
+	}
+
+	lg, err := league.Load(*leagueFile)
+	switch {
+	case err == nil:
+		fmt.Printf("✅ Loaded league with %d members (learner Elo %.0f)\n", len(lg.Members), lg.LearnerElo)
+	case errors.Is(err, fs.ErrNotExist):
+		lg = league.New(*leagueFile)
+		fmt.Println("🆕 New league, scripted opponents until the first freeze")
+	default:
+		log.Fatal(err)
+	}
+
+	opts := snake.DefaultArenaOptions()
+	opts.Snakes = *snakes
+	trainer := league.NewTrainer(agent, lg, opts, *seed)
+
+	// Первый сигнал дожидается сохранения, второй завершает процесс сразу
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		signal.Stop(sig)
+		fmt.Println("\n🛑 Interrupted, saving progress...")
+		trainer.Stop()
+	}()
+
+	wins, games, rounds, scoreSum := 0.0, 0, 0, 0
+	for agent.EpisodeCount() < *episodes {
+		res, ok := trainer.PlayRound()
+		if !ok {
+			break
+		}
+		rounds++
+		scoreSum += res.Score
+		for _, o := range res.Outcomes {
+			wins += o
+			games++
+		}
+
+		if ep := agent.EpisodeCount(); ep%config.SaveCheckpointFreq == 0 || ep == *episodes {
+			fmt.Printf("Episode %d | Avg score: %.2f | Win rate: %.1f%% | Elo: %.0f | Epsilon: %.3f | League: %d\n",
+				ep, float64(scoreSum)/float64(rounds), 100*wins/float64(max(games, 1)),
+				lg.LearnerElo, agent.Epsilon(), len(lg.Members))
+			wins, games, rounds, scoreSum = 0, 0, 0, 0
+			save(agent, lg, *modelFile, *checkpointDir)
+		}
+	}
+	if rounds > 0 || agent.EpisodeCount() < *episodes {
+		save(agent, lg, *modelFile, *checkpointDir)
+	}
+
+	fmt.Printf("\n🏆 League standings (learner Elo %.0f):\n", lg.LearnerElo)
+	for _, m := range lg.Standings() {
+		fmt.Printf("  #%-3d episode %-6d Elo: %6.0f | Games: %4d | Learner win rate: %.1f%%\n",
+			m.ID, m.Episode, m.Elo, m.Games, 100*m.LearnerWinRate())
+	}
+}
+
+// save writes learner model, league index and full training checkpoint
+func save(agent *ai.Agent, lg *league.League, modelFile, checkpointDir string) {
+	if err := agent.SaveModel(modelFile); err != nil {
+		log.Fatal(err)
+	}
+	if err := lg.Save(); err != nil {
+		log.Fatal(err)
+	}
+	if err := agent.SaveCheckpoint(checkpointDir, config.CheckpointReplay, nil); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("💾 Saved learner %s, league and checkpoint %s (episode %d)\n", modelFile, checkpointDir, agent.EpisodeCount())
+}
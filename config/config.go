@@ -30,7 +30,27 @@ const (
 	ExpansionThreshold = 0.9
 	ExpansionIncrement = 2
 
-	PlayingSpeed = 5
+	PlayingSpeed    = 5 // Frames per step in play mode ([-]/[+] adjust)
+	PlayingSpeedMin = 1
+	PlayingSpeedMax = 30
+
+	HumanInputBuffer = 3 // Turns queued between ticks in human mode
+)
+
+// ================================
+// OBSTACLE LAYOUTS
+// ================================
+const (
+	ObstacleLayout = "scatter" // scatter, maze, rooms, walls, mixed (random layout per episode)
+
+	LayoutSpawnClearance = 2    // Free cells kept around spawn in generated layouts
+	MazeOpenness         = 0.35 // Share of maze walls knocked out to create loops
+	RoomMinSize          = 4    // Minimal room side; fields are split while rooms fit
+	RoomDoorWidth        = 2
+	WallSegmentsMin      = 3
+	WallSegmentsMax      = 6
+	WallSegmentLenMin    = 3
+	WallSegmentLenMax    = 7
 )
 
 // ================================
@@ -44,14 +64,46 @@ const (
 	ModelBestName      = "snake_ai_model_best.json"
 	ModelFinalName     = "snake_ai_model_final.json"
 	ModelGenPrefix     = "snake_ai_model_gen"
+	ModelGenExt        = ".json" // ModelBinaryExt saves generations in binary
 	SaveCheckpointFreq = 100
 
+	// Model files ending in ModelBinaryExt are saved in the compact binary
+	// format instead of JSON, e.g. ModelBestName = "snake_ai_model_best.bin"
+	ModelBinaryExt     = ".bin"
+	ModelBinaryFloat64 = false // float32 halves the size, float64 is lossless
+
+	// Generation models (ModelGenPrefix) kept on disk: a file survives if
+	// any rule keeps it, 0 turns a rule off, all rules off keeps everything.
+	// The previous best model is kept as snake_ai_model_best.prev.json.
+	ModelGenIndex         = "snake_ai_model_gen_index.json"
+	RetainLastGenerations = 5
+	RetainEveryGeneration = 10
+	RetainTopGenerations  = 3
+	RetainEvalEpisodes    = 10 // Greedy evaluation per generation, 0 scores by training average
+
+	// Full training state for resuming: agent, networks, replay buffer, scores.
+	// NewGame resumes from it when present; delete the directory to start over.
+	CheckpointDir    = "checkpoint"
+	CheckpointReplay = true // Include replay buffer (large, but resume is exact)
+
 	StateSize    = 28  // ✅ Увеличено с 24 до 28 (добавлено больше информации о пространстве)
 	ActionSize   = 4
 	HiddenLayer1 = 256
 	HiddenLayer2 = 256
 	HiddenLayer3 = 128 // ✅ НОВОЕ: добавлен третий слой
 
+	// Action space: absolute (Up/Right/Down/Left) or relative (turn left/straight/turn right)
+	ActionModeAbsolute = "absolute"
+	ActionModeRelative = "relative"
+	ActionMode         = ActionModeAbsolute
+	RelativeActionSize = 3
+
+	// Optional observation features (changing them requires a new model)
+	PathFeaturesEnabled  = false // +2: BFS path length to food, path exists
+	PathFeatureCount     = 2
+	FloodFeaturesEnabled = false // +8: flood-fill area and tail reachability per move
+	FloodFeatureCount    = 8
+
 	LearningRate  = 0.0003 // ✅ Еще меньше для стабильности
 	BufferSize    = 1000000
 	EpsilonStart  = 1.0
@@ -68,6 +120,102 @@ const (
 	Speed50x = 50.0
 )
 
+// ================================
+// PLANNING (MCTS)
+// ================================
+const (
+	MCTSSimulations = 100
+	MCTSExploration = 1.5
+	MCTSTemperature = 1.0
+
+	PlayPolicyDefault = "dqn" // Play mode policy: dqn, dqn-int8, safe-dqn, mcts, astar, hamilton ([M] cycles)
+
+	HamiltonShortcutMargin  = 3   // Free cells kept between head and tail when shortcutting
+	HamiltonShortcutMaxFill = 0.5 // No shortcuts once snake covers this share of the cycle
+)
+
+// ================================
+// ARENA (MULTI-SNAKE)
+// ================================
+const (
+	ArenaWidth    = 30
+	ArenaHeight   = 20
+	ArenaSnakes   = 4
+	ArenaFood     = 3
+	ArenaPolicies = "safe-dqn,astar,hamilton,dqn" // One per snake: dqn, safe-dqn, mcts, astar, hamilton, human
+)
+
+// ================================
+// HUMAN VS AI RACE
+// ================================
+const (
+	RacePolicy = "safe-dqn" // AI opponent: dqn, safe-dqn, mcts, astar, hamilton
+	RaceGap    = 40         // Pixels between the two boards
+)
+
+// ================================
+// SELF-PLAY LEAGUE
+// ================================
+const (
+	SelfPlayModelName     = "snake_ai_model_selfplay.json"
+	SelfPlayCheckpointDir = "checkpoint_selfplay"
+
+	SelfPlaySnakes    = 3 // Learner plus opponents sampled from the league
+	LeagueFile        = "snake_ai_league.json"
+	LeaguePrefix      = "snake_ai_model_league" // Frozen members, stored like generation checkpoints
+	LeagueMaxSize     = 10                      // Lowest rated member is dropped beyond this
+	LeagueFreezeEvery = 200                     // Learner rounds between freezes
+	LeagueInitialElo  = 1000.0
+	LeagueEloK        = 16.0
+	LeagueMinWeight   = 0.05 // Sampling weight floor so beaten members still get played
+)
+
+// ================================
+// CURRICULUM
+// ================================
+const (
+	CurriculumEnabled     = true // Training fields follow curriculum stages (ignored when a level is selected)
+	CurriculumMinEpisodes = 200  // Episodes in a stage before it can change
+)
+
+// ================================
+// EPISODE RECORDING
+// ================================
+const (
+	RecordEpisodes     = true
+	EpisodeDir         = "episodes"
+	EpisodeExt         = ".jsonl"
+	EpisodeBestPrefix  = "best"
+	EpisodePlayPrefix  = "play"
+	EpisodeHumanPrefix = "human"
+
+	ReplayStepsPerSecond = 8
+	ReplayMaxSpeed       = 512
+)
+
+// ================================
+// IMITATION LEARNING
+// ================================
+const (
+	DemoPrefix     = "demo"       // Episodes of scripted policies recorded by cmd/pretrain
+	DemoPrefixes   = "human,demo" // Episode file prefixes used as demonstrations
+	DemoMargin     = 0.8          // Large-margin gap between demo action and the others
+	DemoReplay     = false        // Keep demonstrations in the replay buffer during training
+	PretrainEpochs = 5
+)
+
+// ================================
+// LEVELS
+// ================================
+const (
+	LevelDefault = ""            // Built-in level name or .lvl file; empty = random field ([L] cycles in menu)
+	UserLevelDir = "user_levels" // Editor saves here; built-ins are embedded from the levels package
+	LevelExt     = ".lvl"
+
+	EditorMinSize = 5
+	EditorMaxSize = InitialFieldWidth * MaxFieldExpansion
+)
+
 // ================================
 // REWARD SYSTEM (улучшена)
 // ================================
@@ -128,6 +276,18 @@ var (
 	ColorSnakeHead       = color.RGBA{100, 255, 100, 255}
 	ColorSnakeHeadBorder = color.RGBA{50, 200, 50, 255}
 
+	ColorCursor = color.RGBA{255, 255, 255, 160}
+
+	// Головы змеек арены; тело рисуется тем же цветом темнее
+	ColorArenaSnakes = []color.RGBA{
+		{100, 255, 100, 255},
+		{90, 160, 255, 255},
+		{255, 140, 220, 255},
+		{255, 170, 60, 255},
+		{180, 120, 255, 255},
+		{80, 230, 230, 255},
+	}
+
 	ColorProgressBg     = color.RGBA{40, 40, 50, 255}
 	ColorProgressBorder = color.RGBA{100, 100, 120, 255}
 )
@@ -142,6 +302,12 @@ const (
 
 	MenuBtnTraining = "[SPACE] - Start Training"
 	MenuBtnPlay     = "[P]     - Play with Trained AI"
+	MenuBtnHuman    = "[H]     - Play Yourself (Arrows/WASD)"
+	MenuBtnReplay   = "[R]     - Replay Last Episode"
+	MenuBtnLevel    = "[L]     - Level: %s"
+	MenuBtnEditor   = "[E]     - Level Editor"
+	MenuBtnArena    = "[A]     - Multi-Snake Arena"
+	MenuBtnRace     = "[V]     - Human vs AI Race"
 	MenuBtnQuit     = "[Q]     - Quit"
 
 	MenuFeatures = "Features:"
@@ -152,6 +318,9 @@ const (
 	MenuFeature5 = "  • 100 episodes = 1 generation"
 
 	MenuControls = "Controls: [1] 1x [2] 5x [3] 10x [4] 50x speed | [ESC] Menu"
+
+	ReplayControls = "[SPACE] Pause | [LEFT/RIGHT] Step | [UP/DOWN] Speed | [0-9 ENTER] Jump | [HOME/END] | [ESC] Menu"
+	EditorControls = "[LMB] Draw | [RMB] Erase | [1] Wall [2] Spawn [3] Food | [ARROWS] Size | [W] Wrap | [D] Direction | [C] Clear | [N] Name | [T] Test | [S] Save | [ESC] Menu"
 )
 
 // ================================
@@ -159,7 +328,27 @@ const (
 // ================================
 
 func GetNeuralLayers() []int {
-	return []int{StateSize, HiddenLayer1, HiddenLayer2, HiddenLayer3, ActionSize}
+	return []int{GetStateSize(), HiddenLayer1, HiddenLayer2, HiddenLayer3, GetActionSize()}
+}
+
+// GetActionSize returns number of actions in configured action mode
+func GetActionSize() int {
+	if ActionMode == ActionModeRelative {
+		return RelativeActionSize
+	}
+	return ActionSize
+}
+
+// GetStateSize returns observation size including enabled optional features
+func GetStateSize() int {
+	size := StateSize
+	if PathFeaturesEnabled {
+		size += PathFeatureCount
+	}
+	if FloodFeaturesEnabled {
+		size += FloodFeatureCount
+	}
+	return size
 }
 
 func GetInitialObstacles() int {
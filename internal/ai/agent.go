@@ -5,6 +5,9 @@ import (
 	"snakes-ml/config"
 )
 
+// MetaActionMode is model metadata key holding action mode of the agent
+const MetaActionMode = "action_mode"
+
 // Agent represents DQN agent with generation system
 type Agent struct {
 	qNetwork          *Network
@@ -23,11 +26,17 @@ type Agent struct {
 	totalReward       float64
 	episodeRewards    []float64
 	lastLoss          float64 // Для отслеживания прогресса обучения
+	actionSize        int
+	actionMode        string
+	rngSrc            *rand.PCG // Saved in checkpoints, so exploration resumes exactly
+	rng               *rand.Rand
 }
 
 // NewAgent creates new DQN agent using configuration
 func NewAgent(stateSize, actionSize int, cfg Config) *Agent {
 	layers := config.GetNeuralLayers()
+	layers[0], layers[len(layers)-1] = stateSize, actionSize
+	src := rand.NewPCG(rand.Uint64(), rand.Uint64())
 
 	return &Agent{
 		qNetwork:          NewNetwork(layers, cfg.LearningRate),
@@ -45,18 +54,60 @@ func NewAgent(stateSize, actionSize int, cfg Config) *Agent {
 		currentGeneration: 1,
 		episodeRewards:    make([]float64, 0, 100),
 		lastLoss:          0,
+		actionSize:        actionSize,
+		actionMode:        cfg.ActionMode,
+		rngSrc:            src,
+		rng:               rand.New(src),
 	}
 }
 
-// SelectAction chooses action using epsilon-greedy strategy
-func (a *Agent) SelectAction(state []float64) int {
+// SelectAction chooses action using epsilon-greedy strategy among
+// actions allowed by mask (nil mask allows all actions)
+func (a *Agent) SelectAction(state []float64, mask []bool) int {
 	// ✅ УЛУЧШЕНО: адаптивный epsilon на основе прогресса
-	if rand.Float64() < a.epsilon {
-		return rand.IntN(config.ActionSize)
+	if a.rng.Float64() < a.epsilon {
+		return randomLegal(a.rng, a.actionSize, mask)
 	}
 
 	qValues := a.qNetwork.Forward(state)
-	return argmax(qValues)
+	return MaskedArgmax(qValues, mask)
+}
+
+// randomLegal returns uniformly random action allowed by mask
+func randomLegal(rng *rand.Rand, n int, mask []bool) int {
+	if mask == nil {
+		return rng.IntN(n)
+	}
+
+	legal := make([]int, 0, n)
+	for i := 0; i < n && i < len(mask); i++ {
+		if mask[i] {
+			legal = append(legal, i)
+		}
+	}
+	if len(legal) == 0 {
+		return rng.IntN(n)
+	}
+	return legal[rng.IntN(len(legal))]
+}
+
+// MaskedArgmax returns index of maximum value among allowed indices,
+// falling back to plain argmax when mask is nil or allows nothing
+func MaskedArgmax(values []float64, mask []bool) int {
+	best := -1
+	for i, v := range values {
+		if mask != nil && (i >= len(mask) || !mask[i]) {
+			continue
+		}
+		if best < 0 || v > values[best] {
+			best = i
+		}
+	}
+
+	if best < 0 {
+		return argmax(values)
+	}
+	return best
 }
 
 // argmax returns index of maximum value
@@ -79,12 +130,13 @@ func argmax(values []float64) int {
 }
 
 // Remember stores experience in replay buffer
-func (a *Agent) Remember(state []float64, action int, reward float64, nextState []float64, done bool) {
+func (a *Agent) Remember(state []float64, action int, reward float64, nextState []float64, done bool, nextMask []bool) {
 	a.replayBuffer.Add(Experience{
 		State:     state,
 		Action:    action,
 		Reward:    reward,
 		NextState: nextState,
+		NextMask:  nextMask,
 		Done:      done,
 	})
 
@@ -97,7 +149,7 @@ func (a *Agent) Train() float64 {
 		return 0
 	}
 
-	batch := a.replayBuffer.Sample(a.batchSize)
+	batch := a.replayBuffer.Sample(a.rng, a.batchSize)
 	totalLoss := 0.0
 
 	for _, exp := range batch {
@@ -110,7 +162,7 @@ func (a *Agent) Train() float64 {
 			// ✅ УЛУЧШЕНО: Double DQN для стабильности
 			// Используем q-network для выбора действия
 			nextQValues := a.qNetwork.Forward(exp.NextState)
-			bestAction := argmax(nextQValues)
+			bestAction := MaskedArgmax(nextQValues, exp.NextMask)
 			
 			// Используем target-network для оценки
 			targetNextQValues := a.targetNetwork.Forward(exp.NextState)
@@ -120,6 +172,10 @@ func (a *Agent) Train() float64 {
 			target[exp.Action] = exp.Reward + a.gamma*maxQ
 		}
 
+		if exp.Demo {
+			applyMargin(target, exp.Action, config.DemoMargin)
+		}
+
 		loss := a.qNetwork.BackwardAndUpdate(exp.State, target)
 		totalLoss += loss
 	}
@@ -181,31 +237,64 @@ func (a *Agent) GetAverageReward(window int) float64 {
 	return sum / float64(count)
 }
 
+// AddDemonstrations puts demonstration transitions into the replay
+// buffer, where they stay for the rest of training
+func (a *Agent) AddDemonstrations(demos []Experience) {
+	for _, exp := range demos {
+		a.replayBuffer.AddDemo(exp)
+	}
+}
+
 // UpdateTargetNetwork copies weights from q-network to target-network
 func (a *Agent) UpdateTargetNetwork() {
 	a.targetNetwork = a.qNetwork.Clone()
 }
 
-// SaveModel saves neural network to file
+// SaveModel saves neural network to file together with the observation
+// encoder, action mode and training progress
 func (a *Agent) SaveModel(filename string) error {
+	a.qNetwork.SetMetadata(MetaActionMode, a.actionMode)
+	info := a.qNetwork.Info()
+	info.Encoder = StateEncoder()
+	info.ActionMode = a.actionMode
+	info.Training = &TrainingInfo{
+		Gamma:        a.gamma,
+		BatchSize:    a.batchSize,
+		BufferSize:   a.replayBuffer.capacity,
+		UpdateFreq:   a.updateFreq,
+		EpsilonDecay: a.epsilonDecay,
+		Epsilon:      a.epsilon,
+		Episodes:     a.episodeCount,
+		Generation:   a.currentGeneration,
+		Steps:        a.stepCount,
+	}
+	a.qNetwork.SetInfo(info)
 	return a.qNetwork.SaveToFile(filename)
 }
 
-// LoadModel loads neural network from file
+// LoadModel loads neural network from file. Models saved before action
+// modes existed are treated as absolute. A model whose input or output
+// size differs from the agent's network is rejected.
 func (a *Agent) LoadModel(filename string) error {
-	err := a.qNetwork.LoadFromFile(filename)
-	if err == nil {
-		a.targetNetwork = a.qNetwork.Clone()
+	layers := a.qNetwork.Layers()
+	loaded, err := LoadNetworkFor(filename, layers[0], layers[len(layers)-1], a.actionMode)
+	if err != nil {
+		return err
 	}
-	return err
+
+	a.qNetwork.assign(loaded)
+	a.targetNetwork = a.qNetwork.Clone()
+	return nil
 }
 
 // Getters
 func (a *Agent) Epsilon() float64           { return a.epsilon }
 func (a *Agent) SetEpsilon(epsilon float64) { a.epsilon = epsilon }
 func (a *Agent) ReplayBufferSize() int      { return a.replayBuffer.Size() }
+func (a *Agent) DemoCount() int             { return a.replayBuffer.DemoCount() }
 func (a *Agent) StepCount() int             { return a.stepCount }
 func (a *Agent) EpisodeCount() int          { return a.episodeCount }
 func (a *Agent) Generation() int            { return a.currentGeneration }
 func (a *Agent) GenerationProgress() int    { return a.episodeCount % a.generationSize }
 func (a *Agent) LastLoss() float64          { return a.lastLoss }
+func (a *Agent) Network() *Network          { return a.qNetwork }
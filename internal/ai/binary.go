@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"snakes-ml/config"
+)
+
+// Binary model layout, all integers and floats little-endian:
+//
+//	magic    [4]byte "SNKM"
+//	version  uint16  same as ModelVersion of the JSON envelope
+//	float    uint8   4 (float32) or 8 (float64)
+//	reserved uint8
+//	header   uint32 length + JSON {format, version, info, metadata}
+//	layers   uint32 count + uint32 per layer
+//	params   weights[i][j][k] then biases[i][k] for every layer i
+//	checksum uint32  CRC-32 (IEEE) of everything above
+const binaryMagic = "SNKM"
+
+// IsBinaryModel reports whether filename is saved in the binary format
+func IsBinaryModel(filename string) bool {
+	return strings.EqualFold(filepath.Ext(filename), config.ModelBinaryExt)
+}
+
+// encodeBinary serializes m with float32 or float64 parameters
+func encodeBinary(m *modelFile, float64s bool) ([]byte, error) {
+	header, err := json.Marshal(modelFile{
+		Format:   m.Format,
+		Version:  m.Version,
+		Info:     m.Info,
+		Metadata: m.Metadata,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal header: %w", err)
+	}
+
+	floatSize := 4
+	if float64s {
+		floatSize = 8
+	}
+	params := 0
+	for i := 0; i+1 < len(m.Layers); i++ {
+		params += (m.Layers[i] + 1) * m.Layers[i+1]
+	}
+
+	var buf bytes.Buffer
+	buf.Grow(16 + len(header) + 4*len(m.Layers) + floatSize*params + 4)
+	buf.WriteString(binaryMagic)
+	le := binary.LittleEndian
+	buf.Write(le.AppendUint16(nil, uint16(m.Version)))
+	buf.Write([]byte{byte(floatSize), 0})
+	buf.Write(le.AppendUint32(nil, uint32(len(header))))
+	buf.Write(header)
+	buf.Write(le.AppendUint32(nil, uint32(len(m.Layers))))
+	for _, size := range m.Layers {
+		buf.Write(le.AppendUint32(nil, uint32(size)))
+	}
+
+	var scratch [8]byte
+	put := func(v float64) {
+		if float64s {
+			le.PutUint64(scratch[:], math.Float64bits(v))
+			buf.Write(scratch[:8])
+		} else {
+			le.PutUint32(scratch[:], math.Float32bits(float32(v)))
+			buf.Write(scratch[:4])
+		}
+	}
+	for i := range m.Weights {
+		for _, row := range m.Weights[i] {
+			for _, w := range row {
+				put(w)
+			}
+		}
+		for _, b := range m.Biases[i] {
+			put(b)
+		}
+	}
+
+	buf.Write(le.AppendUint32(nil, crc32.ChecksumIEEE(buf.Bytes())))
+	return buf.Bytes(), nil
+}
+
+// binaryReader reads little-endian values and remembers the first error
+type binaryReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *binaryReader) next(n int, what string) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data)-r.pos < n {
+		r.err = fmt.Errorf("truncated at offset %d reading %s", r.pos, what)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *binaryReader) uint32(what string) int {
+	b := r.next(4, what)
+	if b == nil {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(b))
+}
+
+// decodeBinary parses, migrates and validates binary model data
+func decodeBinary(data []byte) (*modelFile, error) {
+	if len(data) < len(binaryMagic)+8 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("not a binary model file")
+	}
+	body := data[:len(data)-4]
+	if sum, want := crc32.ChecksumIEEE(body), binary.LittleEndian.Uint32(data[len(data)-4:]); sum != want {
+		return nil, fmt.Errorf("checksum mismatch: file %08x, content %08x", want, sum)
+	}
+
+	r := &binaryReader{data: body, pos: len(binaryMagic)}
+	version := int(binary.LittleEndian.Uint16(r.next(2, "version")))
+	flags := r.next(2, "float size")
+	floatSize := int(flags[0])
+	if floatSize != 4 && floatSize != 8 {
+		return nil, fmt.Errorf("unsupported float size %d", floatSize)
+	}
+
+	var m modelFile
+	header := r.next(r.uint32("header length"), "header")
+	if r.err != nil {
+		return nil, r.err
+	}
+	if err := json.Unmarshal(header, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal header: %w", err)
+	}
+	if m.Version != version {
+		return nil, fmt.Errorf("header version %d differs from file version %d", m.Version, version)
+	}
+
+	n := r.uint32("layer count")
+	if r.err == nil && (n < 2 || n > (len(body)-r.pos)/4) {
+		return nil, fmt.Errorf("invalid layer count %d", n)
+	}
+	m.Layers = make([]int, n)
+	for i := range m.Layers {
+		m.Layers[i] = r.uint32("layer size")
+		if r.err == nil && (m.Layers[i] <= 0 || m.Layers[i] > len(body)) {
+			return nil, fmt.Errorf("layer %d has invalid size %d", i, m.Layers[i])
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// Размеры проверяются до выделения памяти под веса
+	params := 0
+	for i := 0; i+1 < len(m.Layers); i++ {
+		params += (m.Layers[i] + 1) * m.Layers[i+1]
+	}
+	if rest := len(body) - r.pos; params*floatSize != rest {
+		return nil, fmt.Errorf("layers %v need %d parameter bytes, file has %d", m.Layers, params*floatSize, rest)
+	}
+
+	get := func() float64 {
+		b := r.next(floatSize, "parameters")
+		if floatSize == 8 {
+			return math.Float64frombits(binary.LittleEndian.Uint64(b))
+		}
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+	}
+	m.Weights = make([][][]float64, len(m.Layers)-1)
+	m.Biases = make([][]float64, len(m.Layers)-1)
+	for i := range m.Weights {
+		m.Weights[i] = make([][]float64, m.Layers[i])
+		for j := range m.Weights[i] {
+			m.Weights[i][j] = make([]float64, m.Layers[i+1])
+			for k := range m.Weights[i][j] {
+				m.Weights[i][j][k] = get()
+			}
+		}
+		m.Biases[i] = make([]float64, m.Layers[i+1])
+		for k := range m.Biases[i] {
+			m.Biases[i][k] = get()
+		}
+	}
+
+	if err := m.migrate(); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+	return &m, nil
+}
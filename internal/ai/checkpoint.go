@@ -0,0 +1,227 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"snakes-ml/internal/atomicfile"
+)
+
+// CheckpointAgentFile is the state file of an agent checkpoint directory.
+// It is written last and names the network and replay files of the same
+// save, so a crash mid-save leaves the previous checkpoint intact.
+const CheckpointAgentFile = "agent.json"
+
+// Fixed file names of checkpoints written before agent.json named its
+// files; the oldest ones keep networks as JSON
+const (
+	legacyQNetFile       = "qnet.bin"
+	legacyTargetFile     = "target.bin"
+	legacyReplayFile     = "replay.gob.gz"
+	legacyQNetJSONFile   = "qnet.json"
+	legacyTargetJSONFile = "target.json"
+)
+
+// agentState is the training progress of an agent apart from its
+// networks and replay buffer. Training uses plain SGD, so there is no
+// optimizer state beyond the learning rate kept in the config.
+type agentState struct {
+	Epsilon           float64         `json:"epsilon"`
+	StepCount         int             `json:"step_count"`
+	EpisodeCount      int             `json:"episode_count"`
+	CurrentGeneration int             `json:"current_generation"`
+	TotalReward       float64         `json:"total_reward"`
+	EpisodeRewards    []float64       `json:"episode_rewards"`
+	LastLoss          float64         `json:"last_loss"`
+	ActionMode        string          `json:"action_mode"`
+	RNG               []byte          `json:"rng,omitempty"` // Exploration and replay sampling source
+	QNetFile          string          `json:"qnet_file,omitempty"`
+	TargetFile        string          `json:"target_file,omitempty"`
+	ReplayFile        string          `json:"replay_file,omitempty"` // Empty when saved without replay
+	Extra             json.RawMessage `json:"extra,omitempty"`       // Caller's progress, see SaveCheckpoint
+}
+
+// SaveCheckpoint writes everything needed to continue training into dir:
+// counters, epsilon and random state, q- and target networks and, if
+// withReplay is set, the replay buffer. A non-nil extra is stored as JSON
+// in agent.json, so progress kept outside the agent is committed by the
+// same rename. Files of earlier saves are removed once agent.json points
+// at the new ones.
+func (a *Agent) SaveCheckpoint(dir string, withReplay bool, extra any) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create checkpoint directory: %w", err)
+	}
+
+	rng, err := a.rngSrc.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("save random state: %w", err)
+	}
+	seq := time.Now().UnixNano()
+	st := agentState{
+		Epsilon:           a.epsilon,
+		StepCount:         a.stepCount,
+		EpisodeCount:      a.episodeCount,
+		CurrentGeneration: a.currentGeneration,
+		TotalReward:       a.totalReward,
+		EpisodeRewards:    a.episodeRewards,
+		LastLoss:          a.lastLoss,
+		ActionMode:        a.actionMode,
+		RNG:               rng,
+		QNetFile:          fmt.Sprintf("qnet_%d.bin", seq),
+		TargetFile:        fmt.Sprintf("target_%d.bin", seq),
+	}
+	if extra != nil {
+		if st.Extra, err = json.Marshal(extra); err != nil {
+			return fmt.Errorf("marshal checkpoint extra: %w", err)
+		}
+	}
+
+	a.qNetwork.SetMetadata(MetaActionMode, a.actionMode)
+	if err := a.qNetwork.SaveBinary(filepath.Join(dir, st.QNetFile), true); err != nil {
+		return fmt.Errorf("save q-network: %w", err)
+	}
+	if err := a.targetNetwork.SaveBinary(filepath.Join(dir, st.TargetFile), true); err != nil {
+		return fmt.Errorf("save target network: %w", err)
+	}
+	if withReplay {
+		st.ReplayFile = fmt.Sprintf("replay_%d.gob.gz", seq)
+		if err := a.replayBuffer.Save(filepath.Join(dir, st.ReplayFile)); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal agent state: %w", err)
+	}
+	if err := atomicfile.WriteFile(filepath.Join(dir, CheckpointAgentFile), data, 0644); err != nil {
+		return err
+	}
+	return removeStale(dir, st.QNetFile, st.TargetFile, st.ReplayFile)
+}
+
+// removeStale deletes network and replay files in dir other than keep
+func removeStale(dir string, keep ...string) error {
+	var errs []error
+	for _, pattern := range []string{"qnet*", "target*", "replay*"} {
+		files, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if slices.Contains(keep, filepath.Base(file)) {
+				continue
+			}
+			if err := os.Remove(file); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("remove old checkpoint files: %w", err)
+	}
+	return nil
+}
+
+// LoadCheckpoint restores agent saved by SaveCheckpoint and decodes its
+// extra, if it has one, into a non-nil extra. A missing replay file
+// leaves the buffer empty, so checkpoints without it still resume.
+// Networks and replay states must match the agent's input and output
+// sizes; on any error the agent is left unchanged.
+func (a *Agent) LoadCheckpoint(dir string, extra any) error {
+	data, err := os.ReadFile(filepath.Join(dir, CheckpointAgentFile))
+	if err != nil {
+		return fmt.Errorf("read agent state: %w", err)
+	}
+	var st agentState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("parse agent state: %w", err)
+	}
+	if a.actionMode != "" && st.ActionMode != a.actionMode {
+		return fmt.Errorf("checkpoint uses %s actions, agent uses %s", st.ActionMode, a.actionMode)
+	}
+
+	if st.QNetFile == "" {
+		st.QNetFile = legacyName(dir, legacyQNetFile, legacyQNetJSONFile)
+		st.TargetFile = legacyName(dir, legacyTargetFile, legacyTargetJSONFile)
+		if _, err := os.Stat(filepath.Join(dir, legacyReplayFile)); err == nil {
+			st.ReplayFile = legacyReplayFile
+		}
+	}
+
+	q, target := &Network{}, &Network{}
+	if err := q.LoadFromFile(filepath.Join(dir, st.QNetFile)); err != nil {
+		return fmt.Errorf("load q-network: %w", err)
+	}
+	if err := target.LoadFromFile(filepath.Join(dir, st.TargetFile)); err != nil {
+		return fmt.Errorf("load target network: %w", err)
+	}
+
+	layers := a.qNetwork.Layers()
+	in, out := layers[0], layers[len(layers)-1]
+	if err := q.checkFits("checkpoint q-network", in, out, a.actionMode); err != nil {
+		return err
+	}
+	if err := target.checkFits("checkpoint target network", in, out, ""); err != nil {
+		return err
+	}
+
+	src := &rand.PCG{}
+	if st.RNG != nil {
+		if err := src.UnmarshalBinary(st.RNG); err != nil {
+			return fmt.Errorf("restore random state: %w", err)
+		}
+	}
+
+	var replay *ReplayBuffer
+	if st.ReplayFile != "" {
+		replay = NewReplayBuffer(a.replayBuffer.capacity)
+		if err := replay.Load(filepath.Join(dir, st.ReplayFile)); err != nil {
+			return err
+		}
+		if err := replay.checkStates(in); err != nil {
+			return fmt.Errorf("checkpoint replay buffer: %w", err)
+		}
+	}
+
+	if extra != nil && len(st.Extra) > 0 {
+		if err := json.Unmarshal(st.Extra, extra); err != nil {
+			return fmt.Errorf("parse checkpoint extra: %w", err)
+		}
+	}
+
+	if replay != nil {
+		a.replayBuffer = replay
+	} else {
+		a.replayBuffer.Clear()
+	}
+	if st.RNG != nil {
+		a.rngSrc = src
+		a.rng = rand.New(src)
+	}
+	a.qNetwork.assign(q)
+	a.targetNetwork.assign(target)
+	a.epsilon = st.Epsilon
+	a.stepCount = st.StepCount
+	a.episodeCount = st.EpisodeCount
+	a.currentGeneration = st.CurrentGeneration
+	a.totalReward = st.TotalReward
+	a.episodeRewards = st.EpisodeRewards
+	a.lastLoss = st.LastLoss
+	return nil
+}
+
+// legacyName returns name if it exists in dir, otherwise fallback
+func legacyName(dir, name, fallback string) string {
+	if _, err := os.Stat(filepath.Join(dir, name)); errors.Is(err, fs.ErrNotExist) {
+		return fallback
+	}
+	return name
+}
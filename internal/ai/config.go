@@ -12,6 +12,7 @@ type Config struct {
 	Gamma        float64
 	BatchSize    int
 	UpdateFreq   int
+	ActionMode   string // Saved in model metadata, checked on load
 }
 
 // DefaultConfig returns default DQN configuration from central config
@@ -25,5 +26,6 @@ func DefaultConfig() Config {
 		Gamma:        config.Gamma,
 		BatchSize:    config.BatchSize,
 		UpdateFreq:   config.UpdateFreq,
+		ActionMode:   config.ActionMode,
 	}
 }
@@ -0,0 +1,206 @@
+package ai
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"snakes-ml/config"
+)
+
+// Model file format. Version 1 files are the bare {layers, weights,
+// biases, metadata} object written before the envelope existed; they are
+// migrated on load.
+const (
+	ModelFormat  = "snakes-ml/network"
+	ModelVersion = 2
+)
+
+// MetaBestScore is model metadata key holding the record score the model
+// was saved at
+const MetaBestScore = "best_score"
+
+// Activations of the feed-forward network
+const (
+	ActivationReLU   = "relu"
+	ActivationLinear = "linear"
+)
+
+// ModelInfo describes how a network was built and trained
+type ModelInfo struct {
+	Activation   string        `json:"activation"` // Hidden layers
+	Output       string        `json:"output"`     // Output layer
+	Encoder      string        `json:"encoder,omitempty"`
+	ActionMode   string        `json:"action_mode,omitempty"`
+	LearningRate float64       `json:"learning_rate,omitempty"`
+	Training     *TrainingInfo `json:"training,omitempty"`
+	SavedAt      time.Time     `json:"saved_at,omitzero"`
+}
+
+// TrainingInfo holds agent hyperparameters and progress at save time
+type TrainingInfo struct {
+	Gamma        float64 `json:"gamma"`
+	BatchSize    int     `json:"batch_size"`
+	BufferSize   int     `json:"buffer_size"`
+	UpdateFreq   int     `json:"update_freq"`
+	EpsilonDecay float64 `json:"epsilon_decay"`
+	Epsilon      float64 `json:"epsilon"`
+	Episodes     int     `json:"episodes"`
+	Generation   int     `json:"generation"`
+	Steps        int     `json:"steps"`
+}
+
+// StateEncoder describes the observation layout from central config,
+// e.g. "base28+path2+flood8"
+func StateEncoder() string {
+	enc := fmt.Sprintf("base%d", config.StateSize)
+	if config.PathFeaturesEnabled {
+		enc += fmt.Sprintf("+path%d", config.PathFeatureCount)
+	}
+	if config.FloodFeaturesEnabled {
+		enc += fmt.Sprintf("+flood%d", config.FloodFeatureCount)
+	}
+	return enc
+}
+
+// LoadNetworkFor loads a model and checks that it fits an agent with in
+// inputs, out outputs and action mode (empty mode is not checked). Models
+// saved before action modes existed are treated as absolute.
+func LoadNetworkFor(filename string, in, out int, mode string) (*Network, error) {
+	nn := &Network{}
+	if err := nn.LoadFromFile(filename); err != nil {
+		return nil, err
+	}
+	if err := nn.checkFits(filename, in, out, mode); err != nil {
+		return nil, err
+	}
+	return nn, nil
+}
+
+// checkFits reports why nn cannot serve an agent with in inputs, out
+// outputs and action mode; name identifies the model in errors
+func (nn *Network) checkFits(name string, in, out int, mode string) error {
+	saved := nn.Metadata(MetaActionMode)
+	if saved == "" {
+		saved = config.ActionModeAbsolute
+	}
+	if mode != "" && saved != mode {
+		return fmt.Errorf("%s uses %s actions, expected %s", name, saved, mode)
+	}
+
+	layers := nn.Layers()
+	if layers[0] != in || layers[len(layers)-1] != out {
+		enc := nn.Info().Encoder
+		if enc == "" {
+			enc = "unknown encoder"
+		}
+		return fmt.Errorf("%s has %d inputs (%s) and %d outputs, expected %d (%s) and %d",
+			name, layers[0], enc, layers[len(layers)-1], in, StateEncoder(), out)
+	}
+	return nil
+}
+
+// modelFile is the on-disk envelope of a network
+type modelFile struct {
+	Format   string            `json:"format,omitempty"`
+	Version  int               `json:"version,omitempty"`
+	Info     ModelInfo         `json:"info"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	Layers   []int             `json:"layers"`
+	Weights  [][][]float64     `json:"weights"`
+	Biases   [][]float64       `json:"biases"`
+}
+
+// migrate brings an older file up to the current version
+func (m *modelFile) migrate() error {
+	switch {
+	case m.Version == 0 && m.Format == "":
+		// Version 1: only metadata knew the action mode
+		m.Format, m.Version = ModelFormat, 1
+	case m.Format != ModelFormat:
+		return fmt.Errorf("unknown model format %q", m.Format)
+	case m.Version > ModelVersion:
+		return fmt.Errorf("model version %d is newer than supported version %d", m.Version, ModelVersion)
+	}
+
+	if m.Version == 1 {
+		m.Info = ModelInfo{
+			Activation: ActivationReLU,
+			Output:     ActivationLinear,
+			ActionMode: m.Metadata[MetaActionMode],
+		}
+		m.Version = 2
+	}
+	return nil
+}
+
+// validate checks that weight and bias shapes match layer sizes and that
+// all parameters are finite
+func (m *modelFile) validate() error {
+	if len(m.Layers) < 2 {
+		return fmt.Errorf("model has %d layers, need at least input and output", len(m.Layers))
+	}
+	for i, size := range m.Layers {
+		if size <= 0 {
+			return fmt.Errorf("layer %d has size %d", i, size)
+		}
+	}
+	if m.Info.Activation != ActivationReLU || m.Info.Output != ActivationLinear {
+		return fmt.Errorf("unsupported activations %s/%s, network implements %s/%s",
+			m.Info.Activation, m.Info.Output, ActivationReLU, ActivationLinear)
+	}
+
+	if len(m.Weights) != len(m.Layers)-1 || len(m.Biases) != len(m.Layers)-1 {
+		return fmt.Errorf("model has %d weight and %d bias matrices for %d layers, want %d",
+			len(m.Weights), len(m.Biases), len(m.Layers), len(m.Layers)-1)
+	}
+	for i := range m.Weights {
+		in, out := m.Layers[i], m.Layers[i+1]
+		if len(m.Weights[i]) != in {
+			return fmt.Errorf("weights %d have %d rows, layer %d has %d neurons", i, len(m.Weights[i]), i, in)
+		}
+		for j, row := range m.Weights[i] {
+			if len(row) != out {
+				return fmt.Errorf("weights %d row %d has %d columns, layer %d has %d neurons", i, j, len(row), i+1, out)
+			}
+			for k, w := range row {
+				if math.IsNaN(w) || math.IsInf(w, 0) {
+					return fmt.Errorf("weight [%d][%d][%d] is %v", i, j, k, w)
+				}
+			}
+		}
+		if len(m.Biases[i]) != out {
+			return fmt.Errorf("biases %d have %d values, layer %d has %d neurons", i, len(m.Biases[i]), i+1, out)
+		}
+		for k, b := range m.Biases[i] {
+			if math.IsNaN(b) || math.IsInf(b, 0) {
+				return fmt.Errorf("bias [%d][%d] is %v", i, k, b)
+			}
+		}
+	}
+	return nil
+}
+
+// decodeModel parses, migrates and validates JSON or binary model data
+func decodeModel(data []byte) (*modelFile, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("model file is empty")
+	}
+	if bytes.HasPrefix(data, []byte(binaryMagic)) {
+		return decodeBinary(data)
+	}
+
+	var m modelFile
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("unmarshal network: %w", err)
+	}
+	if err := m.migrate(); err != nil {
+		return nil, err
+	}
+	if err := m.validate(); err != nil {
+		return nil, fmt.Errorf("invalid model: %w", err)
+	}
+	return &m, nil
+}
@@ -7,6 +7,10 @@ import (
 	"math/rand/v2"
 	"os"
 	"sync"
+	"time"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/atomicfile"
 )
 
 // Network представляет нейронную сеть с feed-forward архитектурой
@@ -15,6 +19,8 @@ type Network struct {
 	weights      [][][]float64
 	biases       [][]float64
 	learningRate float64
+	metadata     map[string]string
+	info         ModelInfo
 	mu           sync.RWMutex
 }
 
@@ -23,6 +29,7 @@ func NewNetwork(layers []int, learningRate float64) *Network {
 	nn := &Network{
 		layers:       layers,
 		learningRate: learningRate,
+		info:         ModelInfo{Activation: ActivationReLU, Output: ActivationLinear},
 	}
 
 	nn.weights = make([][][]float64, len(layers)-1)
@@ -176,10 +183,18 @@ func (nn *Network) Clone() *Network {
 		weights:      make([][][]float64, len(nn.weights)),
 		biases:       make([][]float64, len(nn.biases)),
 		learningRate: nn.learningRate,
+		info:         nn.info,
 	}
 
 	copy(clone.layers, nn.layers)
 
+	if nn.metadata != nil {
+		clone.metadata = make(map[string]string, len(nn.metadata))
+		for k, v := range nn.metadata {
+			clone.metadata[k] = v
+		}
+	}
+
 	for i := range nn.weights {
 		clone.weights[i] = make([][]float64, len(nn.weights[i]))
 		clone.biases[i] = make([]float64, len(nn.biases[i]))
@@ -194,55 +209,153 @@ func (nn *Network) Clone() *Network {
 	return clone
 }
 
-// SaveToFile сохраняет сеть в JSON файл
+// SaveToFile сохраняет сеть в текущей версии формата: в бинарном виде,
+// если имя оканчивается на config.ModelBinaryExt, иначе в JSON
 func (nn *Network) SaveToFile(filename string) error {
-	nn.mu.RLock()
-	defer nn.mu.RUnlock()
-
-	data, err := json.Marshal(struct {
-		Layers  []int         `json:"layers"`
-		Weights [][][]float64 `json:"weights"`
-		Biases  [][]float64   `json:"biases"`
-	}{
-		Layers:  nn.layers,
-		Weights: nn.weights,
-		Biases:  nn.biases,
-	})
+	if IsBinaryModel(filename) {
+		return nn.SaveBinary(filename, config.ModelBinaryFloat64)
+	}
 
+	data, err := json.Marshal(nn.envelope())
 	if err != nil {
 		return fmt.Errorf("marshal network: %w", err)
 	}
 
-	return os.WriteFile(filename, data, 0644)
+	return atomicfile.WriteFile(filename, data, 0644)
 }
 
-// LoadFromFile загружает сеть из JSON файла
-func (nn *Network) LoadFromFile(filename string) error {
-	nn.mu.Lock()
-	defer nn.mu.Unlock()
+// SaveBinary сохраняет сеть в бинарном формате с float32 или float64
+// параметрами независимо от расширения файла
+func (nn *Network) SaveBinary(filename string, float64s bool) error {
+	data, err := encodeBinary(nn.envelope(), float64s)
+	if err != nil {
+		return fmt.Errorf("encode network: %w", err)
+	}
+
+	return atomicfile.WriteFile(filename, data, 0644)
+}
+
+// envelope собирает файл модели из текущих параметров
+func (nn *Network) envelope() *modelFile {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	info := nn.info
+	info.Activation, info.Output = ActivationReLU, ActivationLinear
+	info.LearningRate = nn.learningRate
+	info.SavedAt = time.Now().UTC().Truncate(time.Second)
+	if info.ActionMode == "" {
+		info.ActionMode = nn.metadata[MetaActionMode]
+	}
 
+	return &modelFile{
+		Format:   ModelFormat,
+		Version:  ModelVersion,
+		Info:     info,
+		Metadata: nn.metadata,
+		Layers:   nn.layers,
+		Weights:  nn.weights,
+		Biases:   nn.biases,
+	}
+}
+
+// LoadFromFile загружает сеть из JSON или бинарного файла (формат
+// определяется по содержимому). Старые версии формата
+// мигрируются, несовпадение размеров слоёв и весов возвращает ошибку,
+// сеть при этом не меняется.
+func (nn *Network) LoadFromFile(filename string) error {
 	data, err := os.ReadFile(filename)
 	if err != nil {
 		return fmt.Errorf("read file: %w", err)
 	}
 
-	var loaded struct {
-		Layers  []int         `json:"layers"`
-		Weights [][][]float64 `json:"weights"`
-		Biases  [][]float64   `json:"biases"`
+	loaded, err := decodeModel(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
 	}
 
-	if err := json.Unmarshal(data, &loaded); err != nil {
-		return fmt.Errorf("unmarshal network: %w", err)
-	}
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
 
 	nn.layers = loaded.Layers
 	nn.weights = loaded.Weights
 	nn.biases = loaded.Biases
+	nn.metadata = loaded.Metadata
+	nn.info = loaded.Info
 
 	return nil
 }
 
+// assign заменяет параметры сети параметрами other (learning rate сохраняется)
+func (nn *Network) assign(other *Network) {
+	other.mu.RLock()
+	defer other.mu.RUnlock()
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+
+	nn.layers = other.layers
+	nn.weights = other.weights
+	nn.biases = other.biases
+	nn.metadata = other.metadata
+	nn.info = other.info
+}
+
+// SetMetadata сохраняет строковое значение в метаданных модели
+func (nn *Network) SetMetadata(key, value string) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+
+	if nn.metadata == nil {
+		nn.metadata = make(map[string]string)
+	}
+	nn.metadata[key] = value
+}
+
+// Metadata возвращает значение из метаданных модели
+func (nn *Network) Metadata(key string) string {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	return nn.metadata[key]
+}
+
+// AllMetadata возвращает копию всех метаданных модели
+func (nn *Network) AllMetadata() map[string]string {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	meta := make(map[string]string, len(nn.metadata))
+	for k, v := range nn.metadata {
+		meta[k] = v
+	}
+	return meta
+}
+
+// Info возвращает описание модели из файла
+func (nn *Network) Info() ModelInfo {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+	return nn.info
+}
+
+// SetInfo задаёт описание модели, сохраняемое вместе с весами
+func (nn *Network) SetInfo(info ModelInfo) {
+	nn.mu.Lock()
+	defer nn.mu.Unlock()
+	nn.info = info
+}
+
+// LayerParams возвращает копию весов [вход][выход] и смещений слоя i
+func (nn *Network) LayerParams(i int) ([][]float64, []float64) {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	weights := make([][]float64, len(nn.weights[i]))
+	for j, row := range nn.weights[i] {
+		weights[j] = append([]float64(nil), row...)
+	}
+	return weights, append([]float64(nil), nn.biases[i]...)
+}
+
 // Layers возвращает архитектуру сети
 func (nn *Network) Layers() []int {
 	nn.mu.RLock()
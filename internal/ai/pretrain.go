@@ -0,0 +1,69 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// PretrainStats summarizes one pass over the demonstrations
+type PretrainStats struct {
+	Loss     float64
+	Accuracy float64 // Share of demo actions the network already ranked first
+}
+
+// applyMargin pushes every other action at least margin below the
+// demonstrated one: regression form of the DQfD large-margin loss
+func applyMargin(target []float64, action int, margin float64) {
+	for a := range target {
+		if a != action && target[a] > target[action]-margin {
+			target[a] = target[action] - margin
+		}
+	}
+}
+
+// Pretrain fits the network to demonstrations before reinforcement
+// learning. Each transition trains the demo action towards its TD target
+// (bootstrapped from a target copy refreshed every epoch) and keeps other
+// actions margin below it, so greedy play imitates the demonstrator.
+func Pretrain(net *Network, demos []Experience, epochs int, gamma, margin float64) []PretrainStats {
+	stats := make([]PretrainStats, 0, epochs)
+	if len(demos) == 0 {
+		return stats
+	}
+
+	order := make([]int, len(demos))
+	for i := range order {
+		order[i] = i
+	}
+
+	for epoch := 1; epoch <= epochs; epoch++ {
+		target := net.Clone()
+		rand.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+
+		var st PretrainStats
+		for _, idx := range order {
+			exp := demos[idx]
+			q := net.Forward(exp.State)
+			if argmax(q) == exp.Action {
+				st.Accuracy++
+			}
+
+			value := exp.Reward
+			if !exp.Done {
+				next := target.Forward(exp.NextState)
+				value += gamma * next[MaskedArgmax(next, exp.NextMask)]
+			}
+			q[exp.Action] = value
+			applyMargin(q, exp.Action, margin)
+
+			st.Loss += net.BackwardAndUpdate(exp.State, q)
+		}
+
+		st.Loss /= float64(len(demos))
+		st.Accuracy /= float64(len(demos))
+		stats = append(stats, st)
+		fmt.Printf("📚 Pretrain epoch %d/%d: loss %.4f, accuracy %.1f%%\n",
+			epoch, epochs, st.Loss, st.Accuracy*100)
+	}
+	return stats
+}
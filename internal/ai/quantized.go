@@ -0,0 +1,153 @@
+package ai
+
+import "math"
+
+// QuantizedNetwork is an int8 inference copy of a Network. Weights are
+// quantized symmetrically with one scale per layer; activations are
+// quantized on the fly with one scale per layer input, products are
+// accumulated in int32 and dequantized before adding float biases.
+type QuantizedNetwork struct {
+	layers  []int
+	weights [][]int8 // Layer i: [out][in], rows contiguous for dot products
+	scales  []float64
+	biases  [][]float64
+}
+
+// Quantize creates an int8 copy of trained network nn
+func Quantize(nn *Network) *QuantizedNetwork {
+	nn.mu.RLock()
+	defer nn.mu.RUnlock()
+
+	q := &QuantizedNetwork{
+		layers:  append([]int(nil), nn.layers...),
+		weights: make([][]int8, len(nn.weights)),
+		scales:  make([]float64, len(nn.weights)),
+		biases:  make([][]float64, len(nn.biases)),
+	}
+	for i, w := range nn.weights {
+		in, out := nn.layers[i], nn.layers[i+1]
+		maxAbs := 0.0
+		for _, row := range w {
+			for _, v := range row {
+				maxAbs = math.Max(maxAbs, math.Abs(v))
+			}
+		}
+		scale := maxAbs / 127
+		if scale == 0 {
+			scale = 1
+		}
+
+		q.scales[i] = scale
+		q.weights[i] = make([]int8, in*out)
+		for j := 0; j < in; j++ {
+			for k := 0; k < out; k++ {
+				q.weights[i][k*in+j] = quantize8(w[j][k] / scale)
+			}
+		}
+		q.biases[i] = append([]float64(nil), nn.biases[i]...)
+	}
+	return q
+}
+
+// quantize8 rounds v to the nearest int8 in [-127, 127]
+func quantize8(v float64) int8 {
+	return int8(math.Max(-127, math.Min(127, math.Round(v))))
+}
+
+// Forward computes Q-values with int8 weights and activations
+func (q *QuantizedNetwork) Forward(input []float64) []float64 {
+	current := input
+	xq := make([]int8, 0, len(input))
+	for i, w := range q.weights {
+		in, out := q.layers[i], q.layers[i+1]
+
+		maxAbs := 0.0
+		for _, v := range current[:in] {
+			maxAbs = math.Max(maxAbs, math.Abs(v))
+		}
+		inScale := maxAbs / 127
+		xq = xq[:0]
+		for _, v := range current[:in] {
+			if inScale == 0 {
+				xq = append(xq, 0)
+			} else {
+				xq = append(xq, quantize8(v/inScale))
+			}
+		}
+
+		next := make([]float64, out)
+		scale := inScale * q.scales[i]
+		for k := 0; k < out; k++ {
+			row := w[k*in : (k+1)*in]
+			var acc int32
+			for j, x := range xq {
+				acc += int32(x) * int32(row[j])
+			}
+
+			sum := float64(acc)*scale + q.biases[i][k]
+			// ReLU for hidden layers, linear output
+			if i < len(q.weights)-1 {
+				sum = relu(sum)
+			}
+			next[k] = sum
+		}
+		current = next
+	}
+	return current
+}
+
+// Layers returns network architecture
+func (q *QuantizedNetwork) Layers() []int {
+	return q.layers
+}
+
+// SizeBytes returns memory taken by parameters: int8 weights, one float32
+// scale per layer and float32 biases as stored on small devices
+func (q *QuantizedNetwork) SizeBytes() int {
+	size := 4 * len(q.scales)
+	for i := range q.weights {
+		size += len(q.weights[i]) + 4*len(q.biases[i])
+	}
+	return size
+}
+
+// QuantizationReport compares a quantized network with its float source
+type QuantizationReport struct {
+	States       int
+	Agreement    int     // States where both pick the same greedy action
+	MaxAbsError  float64 // Largest Q-value difference
+	MeanAbsError float64
+	MaxQ         float64 // Largest float Q-value magnitude, for scale
+}
+
+// AgreementRate returns the share of states with matching greedy actions
+func (r QuantizationReport) AgreementRate() float64 {
+	if r.States == 0 {
+		return 0
+	}
+	return float64(r.Agreement) / float64(r.States)
+}
+
+// CompareQuantized evaluates nn and q on states and reports how often
+// greedy actions agree and how far Q-values drift
+func CompareQuantized(nn *Network, q *QuantizedNetwork, states [][]float64) QuantizationReport {
+	r := QuantizationReport{States: len(states)}
+	errSum, values := 0.0, 0
+	for _, s := range states {
+		want, got := nn.Forward(s), q.Forward(s)
+		if MaskedArgmax(want, nil) == MaskedArgmax(got, nil) {
+			r.Agreement++
+		}
+		for i := range want {
+			diff := math.Abs(want[i] - got[i])
+			r.MaxAbsError = math.Max(r.MaxAbsError, diff)
+			r.MaxQ = math.Max(r.MaxQ, math.Abs(want[i]))
+			errSum += diff
+			values++
+		}
+	}
+	if values > 0 {
+		r.MeanAbsError = errSum / float64(values)
+	}
+	return r
+}
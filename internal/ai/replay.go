@@ -1,8 +1,15 @@
 package ai
 
 import (
+	"compress/gzip"
+	"encoding/gob"
+	"fmt"
+	"io"
 	"math/rand/v2"
+	"os"
 	"sync"
+
+	"snakes-ml/internal/atomicfile"
 )
 
 // Experience represents single training experience
@@ -11,12 +18,16 @@ type Experience struct {
 	Action    int
 	Reward    float64
 	NextState []float64
+	NextMask  []bool // Legal actions in NextState, nil means all
 	Done      bool
+	Demo      bool // Demonstration transition, trained with large-margin loss
 }
 
-// ReplayBuffer implements experience replay buffer
+// ReplayBuffer implements experience replay buffer. Demonstrations are
+// kept apart and never evicted.
 type ReplayBuffer struct {
 	buffer   []Experience
+	demos    []Experience
 	capacity int
 	mu       sync.Mutex
 }
@@ -42,33 +53,55 @@ func (rb *ReplayBuffer) Add(exp Experience) {
 	}
 }
 
-// Sample returns random batch of experiences
-func (rb *ReplayBuffer) Sample(batchSize int) []Experience {
+// AddDemo adds demonstration that stays in the buffer for good
+func (rb *ReplayBuffer) AddDemo(exp Experience) {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
 
-	if len(rb.buffer) < batchSize {
-		batchSize = len(rb.buffer)
+	exp.Demo = true
+	rb.demos = append(rb.demos, exp)
+}
+
+// Sample returns random batch of experiences drawn with rng,
+// demonstrations included
+func (rb *ReplayBuffer) Sample(rng *rand.Rand, batchSize int) []Experience {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	total := len(rb.demos) + len(rb.buffer)
+	if total < batchSize {
+		batchSize = total
 	}
 
 	samples := make([]Experience, batchSize)
-	indices := rand.Perm(len(rb.buffer))[:batchSize]
+	indices := rng.Perm(total)[:batchSize]
 
 	for i, idx := range indices {
-		samples[i] = rb.buffer[idx]
+		if idx < len(rb.demos) {
+			samples[i] = rb.demos[idx]
+		} else {
+			samples[i] = rb.buffer[idx-len(rb.demos)]
+		}
 	}
 
 	return samples
 }
 
-// Size returns current buffer size
+// Size returns current buffer size including demonstrations
 func (rb *ReplayBuffer) Size() int {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
-	return len(rb.buffer)
+	return len(rb.demos) + len(rb.buffer)
 }
 
-// Clear empties the buffer
+// DemoCount returns number of kept demonstrations
+func (rb *ReplayBuffer) DemoCount() int {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return len(rb.demos)
+}
+
+// Clear empties the buffer; demonstrations are kept
 func (rb *ReplayBuffer) Clear() {
 	rb.mu.Lock()
 	defer rb.mu.Unlock()
@@ -81,3 +114,73 @@ func (rb *ReplayBuffer) IsFull() bool {
 	defer rb.mu.Unlock()
 	return len(rb.buffer) >= rb.capacity
 }
+
+// replayFile is the on-disk form of the buffer
+type replayFile struct {
+	Capacity int
+	Buffer   []Experience
+	Demos    []Experience
+}
+
+// Save writes buffer contents, demonstrations included, as gzipped gob
+func (rb *ReplayBuffer) Save(filename string) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	return atomicfile.WriteWith(filename, 0644, func(w io.Writer) error {
+		zw := gzip.NewWriter(w)
+		if err := gob.NewEncoder(zw).Encode(replayFile{rb.capacity, rb.buffer, rb.demos}); err != nil {
+			return fmt.Errorf("encode replay buffer: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress replay buffer: %w", err)
+		}
+		return nil
+	})
+}
+
+// Load replaces buffer contents with a saved buffer. The newest
+// experiences are kept if the saved buffer exceeds current capacity.
+func (rb *ReplayBuffer) Load(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("open replay file: %w", err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return fmt.Errorf("decompress replay buffer: %w", err)
+	}
+	var saved replayFile
+	if err := gob.NewDecoder(zr).Decode(&saved); err != nil {
+		return fmt.Errorf("decode replay buffer: %w", err)
+	}
+
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	if n := len(saved.Buffer); n > rb.capacity {
+		saved.Buffer = saved.Buffer[n-rb.capacity:]
+	}
+	rb.buffer = append(make([]Experience, 0, rb.capacity), saved.Buffer...)
+	rb.demos = saved.Demos
+	return nil
+}
+
+// checkStates reports the first experience whose states do not have
+// stateSize features
+func (rb *ReplayBuffer) checkStates(stateSize int) error {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	for _, exps := range [][]Experience{rb.demos, rb.buffer} {
+		for i, e := range exps {
+			if len(e.State) != stateSize || len(e.NextState) != stateSize {
+				return fmt.Errorf("experience %d has %d/%d state features, expected %d",
+					i, len(e.State), len(e.NextState), stateSize)
+			}
+		}
+	}
+	return nil
+}
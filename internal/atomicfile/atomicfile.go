@@ -0,0 +1,78 @@
+// Package atomicfile writes files via a temporary file and rename, so a
+// crash mid-write leaves either the old or the new content, never a mix
+package atomicfile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BackupSuffix is inserted before the extension of a backup file name
+const BackupSuffix = ".prev"
+
+// WriteFile is an atomic os.WriteFile
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return WriteWith(filename, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// WriteWith creates a temporary file next to filename, lets write fill
+// it, flushes it to disk and renames it over filename. On any error the
+// temporary file is removed and filename is left untouched.
+func WriteWith(filename string, perm os.FileMode, write func(io.Writer) error) (err error) {
+	dir, base := filepath.Split(filename)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+base+".tmp*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err := write(f); err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync %s: %w", filename, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", filename, err)
+	}
+	if err := os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), filename)
+}
+
+// BackupName returns the backup file name of filename, e.g.
+// model.json -> model.prev.json
+func BackupName(filename string) string {
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + BackupSuffix + ext
+}
+
+// Backup copies filename to BackupName(filename), replacing the previous
+// backup. A missing filename is not an error: there is nothing to keep.
+func Backup(filename string) error {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read %s for backup: %w", filename, err)
+	}
+	return WriteFile(BackupName(filename), data, 0644)
+}
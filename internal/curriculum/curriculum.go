@@ -0,0 +1,176 @@
+// Package curriculum moves training through progressively harder fields
+// as the agent's rolling average score improves
+package curriculum
+
+import (
+	"fmt"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+)
+
+// MetaStage is the model metadata key holding the current stage index
+const MetaStage = "curriculum_stage"
+
+// Stage describes environment parameters of one curriculum step
+type Stage struct {
+	Name             string
+	Width            int
+	Height           int
+	WrapAround       bool
+	Layout           snake.Layout
+	ObstaclesMin     int
+	ObstaclesMax     int
+	ObstacleInterval int
+
+	PromoteScore float64 // Average score that moves to the next stage
+	DemoteScore  float64 // Average score that moves back, 0 disables
+}
+
+// DefaultStages goes from a small empty field to walled fields with
+// generated maze, room and wall layouts
+func DefaultStages() []Stage {
+	return []Stage{
+		{Name: "Small open field", Width: 10, Height: 10, WrapAround: true,
+			PromoteScore: 8},
+		{Name: "Small field", Width: 14, Height: 10, WrapAround: true,
+			ObstaclesMin: 1, ObstaclesMax: 2, PromoteScore: 12},
+		{Name: "Standard field", Width: config.InitialFieldWidth, Height: config.InitialFieldHeight, WrapAround: true,
+			ObstaclesMin: config.InitialObstaclesMin, ObstaclesMax: config.InitialObstaclesMax,
+			ObstacleInterval: config.ObstacleAddInterval, PromoteScore: 15, DemoteScore: 4},
+		{Name: "Walled field", Width: config.InitialFieldWidth, Height: config.InitialFieldHeight,
+			ObstaclesMin: config.InitialObstaclesMin, ObstaclesMax: config.InitialObstaclesMax,
+			ObstacleInterval: config.ObstacleAddInterval, PromoteScore: 15, DemoteScore: 4},
+		{Name: "Mixed layouts", Width: config.InitialFieldWidth, Height: config.InitialFieldHeight,
+			Layout: snake.LayoutMixed, ObstacleInterval: config.ObstacleAddInterval, DemoteScore: 4},
+	}
+}
+
+// Curriculum tracks current stage and scores of the episodes played in it
+type Curriculum struct {
+	stages      []Stage
+	stage       int
+	window      int
+	minEpisodes int
+	scores      []int
+	episodes    int // Episodes played in the current stage
+}
+
+// New creates curriculum starting at the first stage. A stage changes
+// only after minEpisodes episodes in it, using the average of the last
+// window scores.
+func New(stages []Stage, window, minEpisodes int) *Curriculum {
+	return &Curriculum{
+		stages:      stages,
+		window:      window,
+		minEpisodes: max(minEpisodes, window),
+		scores:      make([]int, 0, window),
+	}
+}
+
+// NewDefault creates curriculum from default stages and central config
+func NewDefault() *Curriculum {
+	return New(DefaultStages(), config.WindowSize, config.CurriculumMinEpisodes)
+}
+
+// Options returns snake options for a new episode of the current stage
+func (c *Curriculum) Options() snake.Options {
+	st := c.stages[c.stage]
+	opts := snake.DefaultOptions()
+	opts.Width = st.Width
+	opts.Height = st.Height
+	opts.WrapAround = st.WrapAround
+	opts.Layout = st.Layout
+	opts.ObstaclesMin = st.ObstaclesMin
+	opts.ObstaclesMax = st.ObstaclesMax
+	opts.ObstacleInterval = st.ObstacleInterval
+	return opts
+}
+
+// Record adds episode score and changes stage when the rolling average
+// crosses a threshold. Returns true if the stage changed.
+func (c *Curriculum) Record(score int) bool {
+	c.scores = append(c.scores, score)
+	if len(c.scores) > c.window {
+		c.scores = c.scores[1:]
+	}
+	c.episodes++
+
+	if c.episodes < c.minEpisodes {
+		return false
+	}
+
+	st := c.stages[c.stage]
+	avg := c.AverageScore()
+	switch {
+	case c.stage < len(c.stages)-1 && avg >= st.PromoteScore:
+		c.setStage(c.stage + 1)
+		fmt.Printf("🎓 Curriculum promoted to stage %d/%d: %s (avg score %.1f)\n",
+			c.stage+1, len(c.stages), c.stages[c.stage].Name, avg)
+		return true
+	case c.stage > 0 && st.DemoteScore > 0 && avg < st.DemoteScore:
+		c.setStage(c.stage - 1)
+		fmt.Printf("📉 Curriculum demoted to stage %d/%d: %s (avg score %.1f)\n",
+			c.stage+1, len(c.stages), c.stages[c.stage].Name, avg)
+		return true
+	}
+	return false
+}
+
+// SetStage jumps to stage index, e.g. when resuming from a checkpoint
+func (c *Curriculum) SetStage(stage int) error {
+	if stage < 0 || stage >= len(c.stages) {
+		return fmt.Errorf("curriculum stage %d out of range [0, %d)", stage, len(c.stages))
+	}
+	c.setStage(stage)
+	return nil
+}
+
+func (c *Curriculum) setStage(stage int) {
+	c.stage = stage
+	c.scores = c.scores[:0]
+	c.episodes = 0
+}
+
+// Progress is curriculum state kept in training checkpoints
+type Progress struct {
+	Stage    int   `json:"stage"`
+	Scores   []int `json:"scores"`
+	Episodes int   `json:"episodes"`
+}
+
+// Progress returns current stage with the scores recorded in it
+func (c *Curriculum) Progress() Progress {
+	return Progress{Stage: c.stage, Scores: append([]int(nil), c.scores...), Episodes: c.episodes}
+}
+
+// Resume continues from saved progress
+func (c *Curriculum) Resume(p Progress) error {
+	if err := c.SetStage(p.Stage); err != nil {
+		return err
+	}
+	if n := len(p.Scores); n > c.window {
+		p.Scores = p.Scores[n-c.window:]
+	}
+	c.scores = append(c.scores, p.Scores...)
+	c.episodes = p.Episodes
+	return nil
+}
+
+// AverageScore returns average of the recent scores in the current stage
+func (c *Curriculum) AverageScore() float64 {
+	if len(c.scores) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, s := range c.scores {
+		sum += s
+	}
+	return float64(sum) / float64(len(c.scores))
+}
+
+// Getters
+func (c *Curriculum) Stage() int           { return c.stage }
+func (c *Curriculum) StageCount() int      { return len(c.stages) }
+func (c *Curriculum) Current() Stage       { return c.stages[c.stage] }
+func (c *Curriculum) EpisodesInStage() int { return c.episodes }
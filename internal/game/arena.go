@@ -0,0 +1,137 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// arenaView holds a multi-snake round and the drivers of its snakes
+type arenaView struct {
+	arena    *snake.Arena
+	drivers  []playPolicy
+	policies []policy.Policy
+	wins     []int
+	rounds   int
+	over     bool
+}
+
+// startArena creates drivers from ArenaPolicies and starts first round
+func (g *Game) startArena() {
+	av := &arenaView{}
+	for _, key := range strings.Split(config.ArenaPolicies, ",") {
+		p, err := g.newPlayPolicy(strings.TrimSpace(key))
+		if err != nil {
+			fmt.Printf("⚠️ Arena: %v\n", err)
+			continue
+		}
+		av.drivers = append(av.drivers, p)
+		av.policies = append(av.policies, p.policy)
+	}
+	if len(av.drivers) == 0 {
+		return
+	}
+
+	av.wins = make([]int, len(av.drivers))
+	g.arena = av
+	g.newArenaRound()
+	g.state = StateArena
+}
+
+func (g *Game) newArenaRound() {
+	opts := snake.DefaultArenaOptions()
+	opts.Snakes = len(g.arena.drivers)
+	opts.Level = g.level()
+	g.arena.arena = snake.NewArena(opts)
+	g.arena.over = false
+	for _, d := range g.arena.drivers {
+		if kp, ok := d.policy.(*keyboardPolicy); ok {
+			kp.reset()
+		}
+	}
+}
+
+func (g *Game) updateArena() error {
+	av := g.arena
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.state = StateMenu
+		return nil
+	}
+
+	for _, d := range av.drivers {
+		if kp, ok := d.policy.(*keyboardPolicy); ok {
+			kp.poll()
+		}
+	}
+
+	if av.over {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+			g.newArenaRound()
+		}
+		return nil
+	}
+
+	g.adjustPlaySpeed()
+	if g.frameCount%g.playSpeed != 0 {
+		return nil
+	}
+
+	a := av.arena
+	if _, done := a.Step(policy.ArenaActions(a, av.policies)); done {
+		av.over = true
+		av.rounds++
+		if w := a.Winner(); w >= 0 {
+			av.wins[w]++
+			fmt.Printf("🏆 Arena round %d: snake %d (%s) wins with score %d\n",
+				av.rounds, w+1, av.drivers[w].name, a.Score(w))
+		} else {
+			fmt.Printf("🤝 Arena round %d: tie\n", av.rounds)
+		}
+	}
+	return nil
+}
+
+func (g *Game) drawArena(screen *ebiten.Image) {
+	av := g.arena
+	a := av.arena
+	g.renderer.DrawArena(screen, a)
+
+	lines := make([]string, 0, len(av.drivers)+1)
+	for i, d := range av.drivers {
+		status := "alive"
+		if !a.Alive(i) {
+			status = "dead"
+		}
+		lines = append(lines, fmt.Sprintf("P%d %-20s Score: %3d | Length: %3d | Wins: %d | %s",
+			i+1, d.name, a.Score(i), a.Snake(i).Length(), av.wins[i], status))
+	}
+
+	if av.over {
+		result := "Tie!"
+		if w := a.Winner(); w >= 0 {
+			result = fmt.Sprintf("P%d %s wins!", w+1, av.drivers[w].name)
+		}
+		lines = append(lines, result+" [SPACE] Next round | [ESC] Menu")
+	} else {
+		lines = append(lines, fmt.Sprintf("Round %d | Step %d | [ESC] Menu", av.rounds+1, a.Steps()))
+	}
+
+	boxH := float32(len(lines)*16 + 10)
+	vector.FillRect(screen, 10, 10, 560, boxH, config.ColorTextBg, false)
+	for i, line := range lines {
+		y := 15 + i*16
+		if i < len(av.drivers) {
+			col := arenaColor(i)
+			vector.FillRect(screen, 15, float32(y+3), 10, 10, col, false)
+		}
+		ebitenutil.DebugPrintAt(screen, line, 30, y)
+	}
+}
@@ -0,0 +1,177 @@
+package game
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/atomicfile"
+	"snakes-ml/internal/curriculum"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/retention"
+	"snakes-ml/internal/snake"
+)
+
+// trainingState is game-level progress stored in the agent checkpoint,
+// so both are committed together
+type trainingState struct {
+	BestScore    int                  `json:"best_score"`
+	RecentScores []int                `json:"recent_scores"`
+	Curriculum   *curriculum.Progress `json:"curriculum,omitempty"`
+	SavedAt      time.Time            `json:"saved_at"`
+}
+
+// legacyTrainingFile held trainingState next to checkpoints saved before
+// it moved into the agent checkpoint
+const legacyTrainingFile = "training.json"
+
+// saveCheckpoint writes full training state to CheckpointDir
+func (g *Game) saveCheckpoint() {
+	st := trainingState{
+		BestScore:    g.bestScore,
+		RecentScores: g.recentScores,
+		SavedAt:      time.Now(),
+	}
+	if g.curriculum != nil {
+		p := g.curriculum.Progress()
+		st.Curriculum = &p
+	}
+
+	if err := g.agent.SaveCheckpoint(config.CheckpointDir, config.CheckpointReplay, st); err != nil {
+		fmt.Printf("⚠️ Failed to save checkpoint: %v\n", err)
+		return
+	}
+	if err := os.Remove(filepath.Join(config.CheckpointDir, legacyTrainingFile)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("⚠️ Failed to remove old training state: %v\n", err)
+	}
+	g.unsaved = false
+	fmt.Printf("💾 Training state saved: %s (episode %d)\n", config.CheckpointDir, g.agent.EpisodeCount())
+}
+
+// resumeCheckpoint restores training state saved by saveCheckpoint
+func (g *Game) resumeCheckpoint() error {
+	// Older checkpoints keep game progress in a separate file
+	var legacy trainingState
+	data, err := os.ReadFile(filepath.Join(config.CheckpointDir, legacyTrainingFile))
+	if err == nil {
+		err = json.Unmarshal(data, &legacy)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("read training state: %w", err)
+	}
+
+	var st trainingState
+	if err := g.agent.LoadCheckpoint(config.CheckpointDir, &st); err != nil {
+		return err
+	}
+	if st.SavedAt.IsZero() {
+		st = legacy
+	}
+
+	g.bestScore = st.BestScore
+	g.recentScores = append(g.recentScores[:0], st.RecentScores...)
+	if g.curriculum != nil && st.Curriculum != nil {
+		if err := g.curriculum.Resume(*st.Curriculum); err != nil {
+			fmt.Printf("⚠️ Ignoring saved curriculum progress: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// loadBestModel starts from the best saved model when there is no
+// checkpoint, taking curriculum stage from its metadata
+func (g *Game) loadBestModel() {
+	if err := g.agent.LoadModel(config.ModelBestName); err == nil {
+		fmt.Println("✅ Loaded existing model")
+	} else if backup := atomicfile.BackupName(config.ModelBestName); !errors.Is(err, fs.ErrNotExist) && g.agent.LoadModel(backup) == nil {
+		fmt.Printf("⚠️ Best model unreadable (%v), loaded previous best %s\n", err, backup)
+	} else {
+		fmt.Printf("🆕 Created new model (%v)\n", err)
+	}
+
+	if g.curriculum == nil {
+		return
+	}
+	if stage := g.agent.Network().Metadata(curriculum.MetaStage); stage != "" {
+		n, err := strconv.Atoi(stage)
+		if err == nil {
+			err = g.curriculum.SetStage(n)
+		}
+		if err != nil {
+			fmt.Printf("⚠️ Ignoring saved curriculum stage: %v\n", err)
+		} else {
+			fmt.Printf("🎓 Curriculum resumed at stage %d/%d: %s\n",
+				n+1, g.curriculum.StageCount(), g.curriculum.Current().Name)
+		}
+	}
+}
+
+// saveBestModel keeps the previous best model as backup and saves the
+// agent as the new best
+func (g *Game) saveBestModel() {
+	if err := atomicfile.Backup(config.ModelBestName); err != nil {
+		fmt.Printf("⚠️ Failed to back up best model: %v\n", err)
+	}
+	if err := g.agent.SaveModel(config.ModelBestName); err != nil {
+		fmt.Printf("⚠️ Failed to save best model: %v\n", err)
+	}
+}
+
+// loadGenerations reads the generation retention index and picks up
+// generation files saved before it existed
+func (g *Game) loadGenerations() {
+	ix, err := retention.Load(config.ModelGenIndex)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("⚠️ Ignoring generation index: %v\n", err)
+		}
+		ix = retention.New(config.ModelGenIndex)
+	}
+	if err := ix.Discover(config.ModelGenPrefix, config.ModelGenExt); err != nil {
+		fmt.Printf("⚠️ Failed to list generation models: %v\n", err)
+	}
+	g.generations = ix
+}
+
+// retainGeneration scores the just saved generation model and deletes
+// generation files the retention policy no longer keeps
+func (g *Game) retainGeneration(filename string) {
+	score := 0.0
+	if config.RetainEvalEpisodes > 0 {
+		opts := snake.DefaultOptions()
+		if g.curriculum != nil {
+			opts = g.curriculum.Options()
+		}
+		greedy := policy.NewGreedy(g.agent.Network().Clone())
+		score = policy.Evaluate(greedy, opts, config.RetainEvalEpisodes).AvgScore
+	} else {
+		for _, s := range g.recentScores {
+			score += float64(s)
+		}
+		score /= float64(max(len(g.recentScores), 1))
+	}
+
+	g.generations.Add(retention.Snapshot{
+		Generation: g.agent.Generation(),
+		File:       filename,
+		Score:      score,
+		Evaluated:  true,
+	})
+	removed, err := g.generations.Prune(retention.DefaultPolicy())
+	if err != nil {
+		fmt.Printf("⚠️ Failed to delete old generation models: %v\n", err)
+	}
+	if len(removed) > 0 {
+		fmt.Printf("🧹 Generation %d scored %.2f, deleted %d old generation models\n",
+			g.agent.Generation(), score, len(removed))
+	}
+	if err := g.generations.Save(); err != nil {
+		fmt.Printf("⚠️ Failed to save generation index: %v\n", err)
+	}
+}
@@ -0,0 +1,367 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// editorTool is what left mouse button places in the editor
+type editorTool int
+
+const (
+	toolWall editorTool = iota
+	toolSpawn
+	toolFood
+)
+
+var editorToolNames = [...]string{toolWall: "Wall", toolSpawn: "Spawn", toolFood: "Food"}
+
+// editorView holds level being edited
+type editorView struct {
+	name          string
+	width, height int
+	wrap          bool
+	direction     snake.Direction
+	spawn         snake.Point
+	walls         map[snake.Point]bool
+	food          []snake.Point
+
+	tool        editorTool
+	paintWall   bool // value painted while left button is held
+	renaming    bool
+	testing     bool
+	unreachable int
+	message     string
+}
+
+// newEditorView starts editing a copy of level, or an empty field for nil
+func newEditorView(lvl *snake.Level) *editorView {
+	ev := &editorView{
+		width:     config.InitialFieldWidth,
+		height:    config.InitialFieldHeight,
+		wrap:      config.WrapAroundEnabled,
+		direction: snake.Right,
+		spawn:     snake.Point{X: config.InitialFieldWidth / 2, Y: config.InitialFieldHeight / 2},
+		walls:     make(map[snake.Point]bool),
+	}
+
+	if lvl != nil {
+		ev.name = lvl.Name
+		ev.width, ev.height = lvl.Width, lvl.Height
+		ev.wrap = lvl.WrapAround
+		ev.direction = lvl.Direction
+		ev.spawn = lvl.Spawn
+		ev.food = append([]snake.Point(nil), lvl.Food...)
+		for _, p := range lvl.Walls {
+			ev.walls[p] = true
+		}
+	}
+
+	ev.changed()
+	return ev
+}
+
+// level builds level from editor state, walls go in map order
+func (ev *editorView) level() *snake.Level {
+	lvl := &snake.Level{
+		Name:       ev.name,
+		Width:      ev.width,
+		Height:     ev.height,
+		WrapAround: ev.wrap,
+		Spawn:      ev.spawn,
+		Direction:  ev.direction,
+		Food:       append([]snake.Point(nil), ev.food...),
+	}
+	for p := range ev.walls {
+		lvl.Walls = append(lvl.Walls, p)
+	}
+	sort.Slice(lvl.Walls, func(i, j int) bool {
+		a, b := lvl.Walls[i], lvl.Walls[j]
+		return a.Y < b.Y || a.Y == b.Y && a.X < b.X
+	})
+	return lvl
+}
+
+// changed recounts free cells the snake cannot reach from spawn
+func (ev *editorView) changed() {
+	lvl := ev.level()
+	ev.unreachable = 0
+	if lvl.Validate() != nil {
+		return
+	}
+
+	opts := snake.DefaultOptions()
+	opts.Level = lvl
+	s := snake.NewSnakeWithOptions(opts)
+	area, _ := s.ReachableArea(lvl.Spawn)
+	ev.unreachable = lvl.Width*lvl.Height - len(lvl.Walls) - area
+}
+
+// resize changes field size, cutting off walls and food outside of it
+func (ev *editorView) resize(dw, dh int) {
+	ev.width = min(max(ev.width+dw, config.EditorMinSize), config.EditorMaxSize)
+	ev.height = min(max(ev.height+dh, config.EditorMinSize), config.EditorMaxSize)
+
+	inside := func(p snake.Point) bool { return p.X < ev.width && p.Y < ev.height }
+	for p := range ev.walls {
+		if !inside(p) {
+			delete(ev.walls, p)
+		}
+	}
+	food := ev.food[:0]
+	for _, p := range ev.food {
+		if inside(p) {
+			food = append(food, p)
+		}
+	}
+	ev.food = food
+	ev.spawn.X = min(ev.spawn.X, ev.width-1)
+	ev.spawn.Y = min(ev.spawn.Y, ev.height-1)
+	delete(ev.walls, ev.spawn)
+}
+
+// foodIndex returns position of p in food sequence or -1
+func (ev *editorView) foodIndex(p snake.Point) int {
+	for i, f := range ev.food {
+		if f == p {
+			return i
+		}
+	}
+	return -1
+}
+
+func (ev *editorView) removeFood(p snake.Point) {
+	if i := ev.foodIndex(p); i >= 0 {
+		ev.food = append(ev.food[:i], ev.food[i+1:]...)
+	}
+}
+
+// filename returns level file path derived from level name
+func (ev *editorView) filename() string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r == ' ':
+			return '_'
+		}
+		return -1
+	}, strings.ToLower(ev.name))
+	if slug == "" {
+		slug = "custom"
+	}
+	return filepath.Join(config.UserLevelDir, slug+config.LevelExt)
+}
+
+func (g *Game) startEditor() {
+	g.editor = newEditorView(g.levels[g.levelIdx])
+	g.state = StateEditor
+}
+
+func (g *Game) updateEditor() error {
+	ev := g.editor
+
+	if ev.renaming {
+		g.updateEditorName()
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.state = StateMenu
+		return nil
+	}
+
+	switch {
+	case inpututil.IsKeyJustPressed(ebiten.Key1):
+		ev.tool = toolWall
+	case inpututil.IsKeyJustPressed(ebiten.Key2):
+		ev.tool = toolSpawn
+	case inpututil.IsKeyJustPressed(ebiten.Key3):
+		ev.tool = toolFood
+	case inpututil.IsKeyJustPressed(ebiten.KeyLeft):
+		ev.resize(-1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyRight):
+		ev.resize(1, 0)
+	case inpututil.IsKeyJustPressed(ebiten.KeyUp):
+		ev.resize(0, -1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyDown):
+		ev.resize(0, 1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyW):
+		ev.wrap = !ev.wrap
+	case inpututil.IsKeyJustPressed(ebiten.KeyD):
+		ev.direction = (ev.direction + 1) % 4
+	case inpututil.IsKeyJustPressed(ebiten.KeyC):
+		ev.walls = make(map[snake.Point]bool)
+		ev.food = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyN):
+		ev.renaming = true
+		return nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyT):
+		g.testLevel()
+		return nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyS):
+		g.saveLevel()
+	}
+
+	l := g.renderer.layout(g.renderer.Screen(), ev.width, ev.height)
+	cell, ok := l.cellAt(ebiten.CursorPosition())
+	if ok {
+		g.editCell(cell)
+	}
+
+	ev.changed()
+	return nil
+}
+
+// editCell applies mouse buttons to the cell under cursor
+func (g *Game) editCell(cell snake.Point) {
+	ev := g.editor
+
+	if ebiten.IsMouseButtonPressed(ebiten.MouseButtonRight) {
+		delete(ev.walls, cell)
+		ev.removeFood(cell)
+		return
+	}
+
+	if !ebiten.IsMouseButtonPressed(ebiten.MouseButtonLeft) {
+		return
+	}
+	justPressed := inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft)
+
+	switch ev.tool {
+	case toolWall:
+		if justPressed {
+			ev.paintWall = !ev.walls[cell]
+		}
+		if cell == ev.spawn {
+			return
+		}
+		if ev.paintWall {
+			ev.walls[cell] = true
+			ev.removeFood(cell)
+		} else {
+			delete(ev.walls, cell)
+		}
+	case toolSpawn:
+		if !ev.walls[cell] {
+			ev.spawn = cell
+		}
+	case toolFood:
+		if !justPressed || ev.walls[cell] {
+			return
+		}
+		if ev.foodIndex(cell) >= 0 {
+			ev.removeFood(cell)
+		} else {
+			ev.food = append(ev.food, cell)
+		}
+	}
+}
+
+// updateEditorName edits level name from typed characters
+func (g *Game) updateEditorName() {
+	ev := g.editor
+	for _, r := range ebiten.AppendInputChars(nil) {
+		// Отладочный шрифт Ebiten умеет только ASCII
+		if r >= ' ' && r <= '~' && len(ev.name) < 32 {
+			ev.name += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(ev.name) > 0 {
+		ev.name = ev.name[:len(ev.name)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		ev.name = strings.TrimSpace(ev.name)
+		ev.renaming = false
+	}
+}
+
+// testLevel plays edited level with the selected play policy
+func (g *Game) testLevel() {
+	ev := g.editor
+	if err := ev.level().Validate(); err != nil {
+		ev.message = fmt.Sprintf("Cannot test level: %v", err)
+		return
+	}
+
+	ev.testing = true
+	ev.message = ""
+	g.startPlaying()
+}
+
+// stopTesting returns from test play to the editor
+func (g *Game) stopTesting() {
+	g.editor.testing = false
+	g.state = StateEditor
+}
+
+// saveLevel writes edited level to user levels directory and adds it to menu
+func (g *Game) saveLevel() {
+	ev := g.editor
+	if ev.name == "" {
+		ev.name = fmt.Sprintf("Custom %d", len(g.levels))
+	}
+
+	lvl := ev.level()
+	filename := ev.filename()
+	err := os.MkdirAll(config.UserLevelDir, 0755)
+	if err == nil {
+		err = lvl.Save(filename)
+	}
+	if err != nil {
+		ev.message = fmt.Sprintf("Failed to save level: %v", err)
+		fmt.Printf("⚠️ %s\n", ev.message)
+		return
+	}
+
+	g.addLevel(lvl)
+	ev.message = fmt.Sprintf("Level saved: %s", filename)
+	fmt.Printf("💾 %s\n", ev.message)
+}
+
+func (g *Game) drawEditor(screen *ebiten.Image) {
+	ev := g.editor
+	l := g.renderer.DrawLevel(screen, ev.level())
+
+	if cell, ok := l.cellAt(ebiten.CursorPosition()); ok {
+		posX, posY := l.cellPos(cell)
+		size := float32(l.cellSize)
+		vector.StrokeRect(screen, posX, posY, size, size, 2, config.ColorCursor, false)
+	}
+
+	name := ev.name
+	if ev.renaming {
+		name += "_"
+	}
+	if name == "" {
+		name = "(unnamed)"
+	}
+	info := fmt.Sprintf("LEVEL EDITOR | Name: %s | Size: %dx%d | Wrap: %v | Tool: %s | Walls: %d | Food: %d",
+		name, ev.width, ev.height, ev.wrap, editorToolNames[ev.tool], len(ev.walls), len(ev.food))
+	if ev.unreachable > 0 {
+		info += fmt.Sprintf("\nWarning: %d free cells are unreachable from spawn", ev.unreachable)
+	}
+	if ev.message != "" {
+		info += "\n" + ev.message
+	}
+
+	vector.FillRect(screen, 10, 10, float32(g.screenWidth-20), 60, config.ColorTextBg, false)
+	ebitenutil.DebugPrintAt(screen, info, 15, 15)
+
+	controls := config.EditorControls
+	if ev.renaming {
+		controls = "Type level name | [ENTER] Done"
+	}
+	ebitenutil.DebugPrintAt(screen, controls, 15, g.screenHeight-30)
+}
@@ -1,15 +1,28 @@
 package game
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"snakes-ml/config"
 	"snakes-ml/internal/ai"
+	"snakes-ml/internal/curriculum"
+	"snakes-ml/internal/imitation"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/retention"
 	"snakes-ml/internal/snake"
+	"snakes-ml/levels"
 
 	"github.com/hajimehoshi/ebiten/v2"
 	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/hajimehoshi/ebiten/v2/vector"
 )
 
@@ -19,7 +32,20 @@ type Game struct {
 	screenHeight    int
 	state           State
 	snake           *snake.Snake
+	recorder        *snake.Recorder
+	replay          *replayView
+	playPolicies    []playPolicy
+	playPolicyIdx   int
+	playSpeed       int // Frames per step in play mode
+	human           *keyboardPolicy
+	humanPlaying    bool
+	levels          []*snake.Level
+	levelIdx        int
+	editor          *editorView
+	arena           *arenaView
+	race            *raceView
 	agent           *ai.Agent
+	curriculum      *curriculum.Curriculum
 	renderer        *Renderer
 	maxEpisodes     int
 	bestScore       int
@@ -34,6 +60,9 @@ type Game struct {
 	lastUpdateTime  time.Time
 	lastMapSize     string
 	avgReward       float64 // ✅ НОВОЕ: средняя награда
+	unsaved         bool    // Training progressed since the last checkpoint
+	generations     *retention.Index
+	stopRequested   atomic.Bool
 }
 
 // NewGame creates new game instance using config
@@ -48,26 +77,156 @@ func NewGame(screenWidth, screenHeight int) *Game {
 		trainingMode:    true,
 		autoRestart:     true,
 		speedMultiplier: config.Speed1x,
+		playSpeed:       config.PlayingSpeed,
+		human:           newKeyboardPolicy(),
 		lastUpdateTime:  time.Now(),
 	}
 
 	g.renderer = NewRenderer(screenWidth, screenHeight)
 
 	aiConfig := ai.DefaultConfig()
-	g.agent = ai.NewAgent(config.StateSize, config.ActionSize, aiConfig)
+	g.agent = ai.NewAgent(config.GetStateSize(), config.GetActionSize(), aiConfig)
 
-	if err := g.agent.LoadModel(config.ModelBestName); err == nil {
-		fmt.Println("✅ Loaded existing model")
+	if config.CurriculumEnabled {
+		g.curriculum = curriculum.NewDefault()
+	}
+
+	if err := g.resumeCheckpoint(); err == nil {
+		fmt.Printf("♻️ Resumed training from %s: episode %d, generation %d, epsilon %.3f, best score %d\n",
+			config.CheckpointDir, g.agent.EpisodeCount(), g.agent.Generation(), g.agent.Epsilon(), g.bestScore)
 	} else {
-		fmt.Println("🆕 Created new model")
+		if !errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("⚠️ Ignoring checkpoint: %v\n", err)
+		}
+		g.loadBestModel()
+	}
+
+	if config.DemoReplay && g.agent.DemoCount() == 0 {
+		demos, episodes, err := imitation.LoadDir(config.EpisodeDir, strings.Split(config.DemoPrefixes, ","))
+		if err != nil {
+			fmt.Printf("⚠️ Failed to load demonstrations: %v\n", err)
+		}
+		g.agent.AddDemonstrations(demos)
+		fmt.Printf("📚 Added %d demonstration transitions from %d episodes\n", len(demos), episodes)
 	}
 
+	for _, key := range []string{"dqn", "dqn-int8", "safe-dqn", "mcts", "astar", "hamilton"} {
+		p, _ := g.newPlayPolicy(key)
+		if key == config.PlayPolicyDefault {
+			g.playPolicyIdx = len(g.playPolicies)
+		}
+		g.playPolicies = append(g.playPolicies, p)
+	}
+
+	g.loadGenerations()
+	g.loadLevels()
+
 	return g
 }
 
+// loadLevels collects built-in levels and level files from UserLevelDir
+func (g *Game) loadLevels() {
+	g.levels = []*snake.Level{nil}
+	for _, name := range levels.Names() {
+		if lvl, err := levels.Load(name); err == nil {
+			g.addLevel(lvl)
+		} else {
+			fmt.Printf("⚠️ Failed to load level %s: %v\n", name, err)
+		}
+	}
+
+	files, _ := filepath.Glob(filepath.Join(config.UserLevelDir, "*"+config.LevelExt))
+	for _, f := range files {
+		if lvl, err := snake.LoadLevel(f); err == nil {
+			if lvl.Name == "" {
+				lvl.Name = strings.TrimSuffix(filepath.Base(f), config.LevelExt)
+			}
+			g.addLevel(lvl)
+		} else {
+			fmt.Printf("⚠️ Failed to load level %s: %v\n", f, err)
+		}
+	}
+
+	g.levelIdx = 0
+	if config.LevelDefault != "" {
+		lvl, err := levels.Find(config.LevelDefault)
+		if err != nil {
+			fmt.Printf("⚠️ Failed to load level %s: %v\n", config.LevelDefault, err)
+			return
+		}
+		g.addLevel(lvl)
+	}
+}
+
+// addLevel adds level to menu list, replacing level with the same name,
+// and selects it
+func (g *Game) addLevel(lvl *snake.Level) {
+	for i, other := range g.levels {
+		if other != nil && other.Name == lvl.Name {
+			g.levels[i] = lvl
+			g.levelIdx = i
+			return
+		}
+	}
+	g.levels = append(g.levels, lvl)
+	g.levelIdx = len(g.levels) - 1
+}
+
+// level returns level for new episodes: edited level during test play,
+// otherwise level selected in menu (nil for random field)
+func (g *Game) level() *snake.Level {
+	if g.editor != nil && g.editor.testing {
+		return g.editor.level()
+	}
+	return g.levels[g.levelIdx]
+}
+
+// levelName returns display name of selected level
+func (g *Game) levelName() string {
+	if lvl := g.level(); lvl != nil {
+		return lvl.Name
+	}
+	return "Random"
+}
+
+// playPolicy is a policy selectable in play mode
+type playPolicy struct {
+	key    string
+	name   string
+	policy policy.Policy
+}
+
+// newPlayPolicy creates policy by config key. Scripted policies keep
+// per-snake state, so every snake needs its own instance.
+func (g *Game) newPlayPolicy(key string) (playPolicy, error) {
+	net := g.agent.Network()
+	switch key {
+	case "dqn":
+		return playPolicy{key, "Greedy DQN", policy.NewGreedy(net)}, nil
+	case "dqn-int8":
+		return playPolicy{key, "Greedy DQN int8", policy.NewGreedy(ai.Quantize(net))}, nil
+	case "safe-dqn":
+		return playPolicy{key, "DQN + safety filter", policy.NewSafeFilter(policy.NewGreedy(net))}, nil
+	case "mcts":
+		return playPolicy{key, fmt.Sprintf("MCTS x%d", config.MCTSSimulations), policy.NewMCTS(net, policy.DefaultMCTSConfig())}, nil
+	case "astar":
+		return playPolicy{key, "A* baseline", policy.NewAStar()}, nil
+	case "hamilton":
+		return playPolicy{key, "Hamiltonian cycle", policy.NewHamiltonian()}, nil
+	case "human":
+		return playPolicy{key, "Human", newKeyboardPolicy()}, nil
+	}
+	return playPolicy{}, fmt.Errorf("unknown policy %q", key)
+}
+
 func (g *Game) Update() error {
 	g.frameCount++
 
+	if g.stopRequested.Load() || ebiten.IsWindowBeingClosed() {
+		g.shutdown()
+		return ebiten.Termination
+	}
+
 	switch g.state {
 	case StateMenu:
 		return g.updateMenu()
@@ -77,6 +236,14 @@ func (g *Game) Update() error {
 		return g.updatePlaying()
 	case StateGameOver:
 		return g.updateGameOver()
+	case StateReplay:
+		return g.updateReplay()
+	case StateEditor:
+		return g.updateEditor()
+	case StateArena:
+		return g.updateArena()
+	case StateRace:
+		return g.updateRace()
 	}
 
 	return nil
@@ -87,14 +254,54 @@ func (g *Game) updateMenu() error {
 		g.startTraining()
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyP) {
+		g.humanPlaying = false
 		g.startPlaying()
 	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) {
+		g.humanPlaying = true
+		g.startPlaying()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyR) {
+		g.startLatestReplay()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyL) {
+		g.levelIdx = (g.levelIdx + 1) % len(g.levels)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyE) {
+		g.startEditor()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyA) {
+		g.startArena()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyV) {
+		g.startRace()
+	}
 	if ebiten.IsKeyPressed(ebiten.KeyQ) {
+		g.shutdown()
 		return ebiten.Termination
 	}
 	return nil
 }
 
+// Stop asks the game to save progress and exit on the next update.
+// Safe to call from other goroutines, e.g. a signal handler.
+func (g *Game) Stop() {
+	g.stopRequested.Store(true)
+}
+
+// shutdown saves training progress made since the last checkpoint and
+// the recording of an unfinished play episode
+func (g *Game) shutdown() {
+	if g.unsaved {
+		g.saveCheckpoint()
+	}
+	if g.state == StatePlaying {
+		g.saveEpisode(fmt.Sprintf("%s_%s", g.playPrefix(), time.Now().Format("20060102_150405")))
+		g.recorder = nil
+	}
+	fmt.Println("👋 Bye")
+}
+
 func (g *Game) updateTraining() error {
 	if ebiten.IsKeyPressed(ebiten.Key1) {
 		g.speedMultiplier = config.Speed1x
@@ -119,11 +326,12 @@ func (g *Game) updateTraining() error {
 		}
 
 		state := g.snake.GetState()
-		action := g.agent.SelectAction(state)
+		action := g.agent.SelectAction(state, g.snake.LegalActions())
 		reward, done := g.snake.Step(action)
 		nextState := g.snake.GetState()
 
-		g.agent.Remember(state, action, reward, nextState, done)
+		g.agent.Remember(state, action, reward, nextState, done, g.snake.LegalActions())
+		g.unsaved = true
 
 		if g.agent.ReplayBufferSize() >= config.MinBufferSize {
 			g.agent.Train()
@@ -153,24 +361,31 @@ func (g *Game) updateTraining() error {
 
 func (g *Game) updatePlaying() error {
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-		g.state = StateMenu
+		g.leavePlaying()
 		return nil
 	}
+	if g.humanPlaying {
+		g.human.poll()
+	} else if inpututil.IsKeyJustPressed(ebiten.KeyM) {
+		g.playPolicyIdx = (g.playPolicyIdx + 1) % len(g.playPolicies)
+	}
+	g.adjustPlaySpeed()
 
-	if g.frameCount%config.PlayingSpeed == 0 {
+	if g.frameCount%g.playSpeed == 0 {
 		if g.snake == nil {
 			g.startNewEpisode()
 		}
 
-		oldEpsilon := g.agent.Epsilon()
-		g.agent.SetEpsilon(0)
-		action := g.agent.SelectAction(g.snake.GetState())
-		g.agent.SetEpsilon(oldEpsilon)
+		var p policy.Policy = g.playPolicies[g.playPolicyIdx].policy
+		if g.humanPlaying {
+			p = g.human
+		}
 
-		_, done := g.snake.Step(action)
+		_, done := g.snake.Step(p.SelectAction(g.snake))
 		g.currentScore = g.snake.Score()
 
 		if done {
+			g.saveEpisode(fmt.Sprintf("%s_%s", g.playPrefix(), time.Now().Format("20060102_150405")))
 			g.state = StateGameOver
 		}
 	}
@@ -178,36 +393,96 @@ func (g *Game) updatePlaying() error {
 	return nil
 }
 
+// playPrefix returns file name prefix of play mode recordings
+func (g *Game) playPrefix() string {
+	if g.humanPlaying {
+		return config.EpisodeHumanPrefix
+	}
+	return config.EpisodePlayPrefix
+}
+
+// adjustPlaySpeed changes frames per step with [-] and [+]
+func (g *Game) adjustPlaySpeed() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyMinus) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadSubtract) {
+		g.playSpeed = min(g.playSpeed+1, config.PlayingSpeedMax)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEqual) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadAdd) {
+		g.playSpeed = max(g.playSpeed-1, config.PlayingSpeedMin)
+	}
+}
+
 func (g *Game) updateGameOver() error {
 	if ebiten.IsKeyPressed(ebiten.KeySpace) {
 		g.startPlaying()
 	}
 	if ebiten.IsKeyPressed(ebiten.KeyEscape) {
-		g.state = StateMenu
+		g.leavePlaying()
 	}
 	return nil
 }
 
+// leavePlaying returns to the editor after test play, otherwise to menu
+func (g *Game) leavePlaying() {
+	if g.editor != nil && g.editor.testing {
+		g.stopTesting()
+		return
+	}
+	g.state = StateMenu
+}
+
 func (g *Game) startTraining() {
 	g.state = StateTraining
+	g.trainingMode = true
 	g.startNewEpisode()
 }
 
 func (g *Game) startPlaying() {
 	g.state = StatePlaying
 	g.trainingMode = false
+	g.human.reset()
+
+	// The int8 copy is a snapshot, requantize what training has learned since
+	for i, p := range g.playPolicies {
+		if p.key == "dqn-int8" {
+			g.playPolicies[i], _ = g.newPlayPolicy(p.key)
+		}
+	}
 	g.startNewEpisode()
 }
 
 func (g *Game) startNewEpisode() {
-	g.snake = snake.NewSnake(
-		config.InitialFieldWidth,
-		config.InitialFieldHeight,
-		config.WrapAroundEnabled,
-		config.DynamicSizeEnabled,
-	)
+	opts := snake.DefaultOptions()
+	if g.trainingMode && g.curriculum != nil {
+		opts = g.curriculum.Options()
+	}
+	opts.Level = g.level()
+	g.snake = snake.NewSnakeWithOptions(opts)
 	g.currentScore = 0
 	g.lastMapSize = fmt.Sprintf("%dx%d", g.snake.Width(), g.snake.Height())
+
+	g.recorder = nil
+	if config.RecordEpisodes {
+		g.recorder = g.snake.StartRecording()
+	}
+}
+
+// saveEpisode writes current episode recording to episodes directory
+func (g *Game) saveEpisode(name string) {
+	if g.recorder == nil {
+		return
+	}
+
+	if err := os.MkdirAll(config.EpisodeDir, 0755); err != nil {
+		fmt.Printf("⚠️ Failed to create episode directory: %v\n", err)
+		return
+	}
+
+	filename := filepath.Join(config.EpisodeDir, name+config.EpisodeExt)
+	if err := g.recorder.Save(filename); err != nil {
+		fmt.Printf("⚠️ Failed to save episode: %v\n", err)
+		return
+	}
+	fmt.Printf("🎬 Episode saved: %s\n", filename)
 }
 
 func (g *Game) handleEpisodeEnd() {
@@ -219,18 +494,28 @@ func (g *Game) handleEpisodeEnd() {
 
 	g.agent.EndEpisode()
 
+	// Уровень из меню заменяет поле этапа, такие эпизоды не влияют на этап
+	if g.curriculum != nil && g.level() == nil {
+		g.curriculum.Record(score)
+		g.agent.Network().SetMetadata(curriculum.MetaStage, strconv.Itoa(g.curriculum.Stage()))
+	}
+
 	if score > g.bestScore {
 		g.bestScore = score
-		g.agent.SaveModel(config.ModelBestName)
+		g.agent.Network().SetMetadata(ai.MetaBestScore, strconv.Itoa(score))
+		g.saveBestModel()
 		fmt.Printf("🏆 New record: %d (episode %d, generation %d)\n",
 			score, g.agent.EpisodeCount(), g.agent.Generation())
+		g.saveEpisode(fmt.Sprintf("%s_score%d_ep%d", config.EpisodeBestPrefix, score, g.agent.EpisodeCount()))
 	}
 
 	if g.agent.EpisodeCount()%config.SaveCheckpointFreq == 0 {
-		filename := fmt.Sprintf("%s%d.json", config.ModelGenPrefix, g.agent.Generation())
+		filename := fmt.Sprintf("%s%d%s", config.ModelGenPrefix, g.agent.Generation(), config.ModelGenExt)
 		g.agent.SaveModel(filename)
 		fmt.Printf("💾 Generation %d completed. Checkpoint saved: %s\n",
 			g.agent.Generation(), filename)
+		g.retainGeneration(filename)
+		g.saveCheckpoint()
 	}
 
 	if g.autoRestart {
@@ -255,9 +540,15 @@ func (g *Game) updateStatsText() {
 	// ✅ ОБНОВЛЕНО: добавлена статистика loss
 	g.avgReward = g.agent.GetAverageReward(100)
 
+	stage := "off"
+	if g.curriculum != nil {
+		stage = fmt.Sprintf("%d/%d %s", g.curriculum.Stage()+1, g.curriculum.StageCount(), g.curriculum.Current().Name)
+	}
+
 	g.statsText = fmt.Sprintf(
 		"Gen: %d (%d/%d) | Ep: %d/%d | Score: %d | Avg: %.1f | Best: %d\n"+
-			"ε: %.4f | Loss: %.4f | Buf: %d | Map: %s | Occ: %.0f%% | x%.0f",
+			"ε: %.4f | Loss: %.4f | Buf: %d | Map: %s | Occ: %.0f%% | x%.0f\n"+
+			"Curriculum: %s",
 		g.agent.Generation(),
 		g.agent.GenerationProgress(),
 		config.EpisodesPerGen,
@@ -272,6 +563,7 @@ func (g *Game) updateStatsText() {
 		g.lastMapSize,
 		occupancy,
 		g.speedMultiplier,
+		stage,
 	)
 }
 
@@ -287,6 +579,14 @@ func (g *Game) Draw(screen *ebiten.Image) {
 		g.drawPlaying(screen)
 	case StateGameOver:
 		g.drawGameOver(screen)
+	case StateReplay:
+		g.drawReplay(screen)
+	case StateEditor:
+		g.drawEditor(screen)
+	case StateArena:
+		g.drawArena(screen)
+	case StateRace:
+		g.drawRace(screen)
 	}
 }
 
@@ -308,21 +608,27 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 
 	buttonX := centerX - 150
 	ebitenutil.DebugPrintAt(screen, config.MenuBtnTraining, buttonX, startY+130)
-	ebitenutil.DebugPrintAt(screen, config.MenuBtnPlay, buttonX, startY+160)
-	ebitenutil.DebugPrintAt(screen, config.MenuBtnQuit, buttonX, startY+190)
-
-	ebitenutil.DebugPrintAt(screen, separator, centerX-sepWidth/2, startY+230)
-
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Best Score: %d", g.bestScore), buttonX, startY+270)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Episodes Trained: %d", g.agent.EpisodeCount()), buttonX, startY+300)
-	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Generations: %d", g.agent.Generation()), buttonX, startY+330)
-
-	ebitenutil.DebugPrintAt(screen, config.MenuFeatures, buttonX, startY+380)
-	ebitenutil.DebugPrintAt(screen, config.MenuFeature1, buttonX, startY+410)
-	ebitenutil.DebugPrintAt(screen, config.MenuFeature2, buttonX, startY+440)
-	ebitenutil.DebugPrintAt(screen, config.MenuFeature3, buttonX, startY+470)
-	ebitenutil.DebugPrintAt(screen, config.MenuFeature4, buttonX, startY+500)
-	ebitenutil.DebugPrintAt(screen, config.MenuFeature5, buttonX, startY+530)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnPlay, buttonX, startY+155)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnHuman, buttonX, startY+180)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnReplay, buttonX, startY+205)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf(config.MenuBtnLevel, g.levelName()), buttonX, startY+230)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnEditor, buttonX, startY+255)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnArena, buttonX, startY+280)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnRace, buttonX, startY+305)
+	ebitenutil.DebugPrintAt(screen, config.MenuBtnQuit, buttonX, startY+330)
+
+	ebitenutil.DebugPrintAt(screen, separator, centerX-sepWidth/2, startY+350)
+
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Best Score: %d", g.bestScore), buttonX, startY+375)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Episodes Trained: %d", g.agent.EpisodeCount()), buttonX, startY+395)
+	ebitenutil.DebugPrintAt(screen, fmt.Sprintf("Generations: %d", g.agent.Generation()), buttonX, startY+415)
+
+	ebitenutil.DebugPrintAt(screen, config.MenuFeatures, buttonX, startY+445)
+	ebitenutil.DebugPrintAt(screen, config.MenuFeature1, buttonX, startY+465)
+	ebitenutil.DebugPrintAt(screen, config.MenuFeature2, buttonX, startY+485)
+	ebitenutil.DebugPrintAt(screen, config.MenuFeature3, buttonX, startY+505)
+	ebitenutil.DebugPrintAt(screen, config.MenuFeature4, buttonX, startY+525)
+	ebitenutil.DebugPrintAt(screen, config.MenuFeature5, buttonX, startY+545)
 
 	info := config.MenuControls
 	infoWidth := len(info) * 6
@@ -331,7 +637,7 @@ func (g *Game) drawMenu(screen *ebiten.Image) {
 
 func (g *Game) drawTraining(screen *ebiten.Image) {
 	if g.snake != nil {
-		g.renderer.DrawSnake(screen, g.snake)
+		g.renderer.DrawSnake(screen, g.snake, g.renderer.Screen())
 	}
 
 	if g.statsText != "" {
@@ -348,10 +654,18 @@ func (g *Game) drawTraining(screen *ebiten.Image) {
 
 func (g *Game) drawPlaying(screen *ebiten.Image) {
 	if g.snake != nil {
-		g.renderer.DrawSnake(screen, g.snake)
+		g.renderer.DrawSnake(screen, g.snake, g.renderer.Screen())
 	}
 
-	scoreText := fmt.Sprintf("Score: %d | Best: %d", g.currentScore, g.bestScore)
+	mode := g.playPolicies[g.playPolicyIdx].name + " [M]"
+	if g.humanPlaying {
+		mode = "Human [ARROWS/WASD]"
+	}
+	scoreText := fmt.Sprintf("Score: %d | Best: %d | Policy: %s | Tick: %d frames [-/+]",
+		g.currentScore, g.bestScore, mode, g.playSpeed)
+	if g.editor != nil && g.editor.testing {
+		scoreText += " | Testing level [ESC] Editor"
+	}
 	textWidth := float32(len(scoreText) * 6)
 	vector.FillRect(screen, 10, 10, textWidth+20, 35, config.ColorTextBg, false)
 	ebitenutil.DebugPrintAt(screen, scoreText, 15, 15)
@@ -359,7 +673,7 @@ func (g *Game) drawPlaying(screen *ebiten.Image) {
 
 func (g *Game) drawGameOver(screen *ebiten.Image) {
 	if g.snake != nil {
-		g.renderer.DrawSnake(screen, g.snake)
+		g.renderer.DrawSnake(screen, g.snake, g.renderer.Screen())
 	}
 
 	vector.FillRect(screen, 0, 0, float32(g.screenWidth), float32(g.screenHeight), config.ColorTextBg, false)
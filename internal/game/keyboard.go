@@ -0,0 +1,64 @@
+package game
+
+import (
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+)
+
+// keyboardPolicy steers a snake with arrow keys or WASD. Turns pressed
+// between ticks are queued, so a quick double turn is not lost.
+type keyboardPolicy struct {
+	queue []snake.Direction
+}
+
+func newKeyboardPolicy() *keyboardPolicy {
+	return &keyboardPolicy{queue: make([]snake.Direction, 0, config.HumanInputBuffer)}
+}
+
+var directionKeys = map[ebiten.Key]snake.Direction{
+	ebiten.KeyArrowUp:    snake.Up,
+	ebiten.KeyW:          snake.Up,
+	ebiten.KeyArrowRight: snake.Right,
+	ebiten.KeyD:          snake.Right,
+	ebiten.KeyArrowDown:  snake.Down,
+	ebiten.KeyS:          snake.Down,
+	ebiten.KeyArrowLeft:  snake.Left,
+	ebiten.KeyA:          snake.Left,
+}
+
+// poll queues direction keys pressed this frame; call it every Update
+func (p *keyboardPolicy) poll() {
+	for key, dir := range directionKeys {
+		if !inpututil.IsKeyJustPressed(key) {
+			continue
+		}
+		if n := len(p.queue); n > 0 && p.queue[n-1] == dir {
+			continue
+		}
+		if len(p.queue) < config.HumanInputBuffer {
+			p.queue = append(p.queue, dir)
+		}
+	}
+}
+
+// reset drops queued turns, e.g. when a new episode starts
+func (p *keyboardPolicy) reset() {
+	p.queue = p.queue[:0]
+}
+
+// SelectAction takes the next queued turn, skipping ones that would
+// reverse or keep the current direction; moves straight when none is left
+func (p *keyboardPolicy) SelectAction(s *snake.Snake) int {
+	current := s.CurrentDirection()
+	for len(p.queue) > 0 {
+		dir := p.queue[0]
+		p.queue = p.queue[1:]
+		if dir != current && !dir.IsOpposite(current) {
+			return s.ActionFor(dir)
+		}
+	}
+	return s.ActionFor(current)
+}
@@ -0,0 +1,178 @@
+package game
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// Доски гонки: слева человек, справа агент
+const (
+	raceHuman = iota
+	raceAI
+)
+
+// raceView is a human vs AI race on two boards with identical fields
+type raceView struct {
+	boards [2]*snake.Snake
+	done   [2]bool
+	ai     playPolicy
+	wins   [2]int
+	rounds int
+	winner int // Board index, -1 on a tie
+	over   bool
+}
+
+// startRace creates the AI driver from RacePolicy and starts first race
+func (g *Game) startRace() {
+	p, err := g.newPlayPolicy(config.RacePolicy)
+	if err != nil {
+		fmt.Printf("⚠️ Race: %v\n", err)
+		return
+	}
+	g.race = &raceView{ai: p}
+	g.newRaceRound()
+	g.state = StateRace
+}
+
+// newRaceRound creates two boards from one seed. Food comes from a shared
+// sequence and the field neither grows nor gains obstacles during play, so
+// both players see the same food and obstacles whatever they do.
+func (g *Game) newRaceRound() {
+	opts := snake.DefaultOptions()
+	opts.Level = g.level()
+	opts.Seed = rand.Uint64()
+	opts.DynamicSize = false
+	opts.ObstacleInterval = 0
+
+	width, height := opts.Width, opts.Height
+	if opts.Level != nil {
+		width, height = opts.Level.Width, opts.Level.Height
+	}
+	opts.FoodSequence = snake.NewFoodSequence(opts.Seed, width, height)
+
+	rv := g.race
+	for i := range rv.boards {
+		rv.boards[i] = snake.NewSnakeWithOptions(opts)
+		rv.done[i] = false
+	}
+	rv.over = false
+	g.human.reset()
+}
+
+func (g *Game) updateRace() error {
+	rv := g.race
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.state = StateMenu
+		return nil
+	}
+
+	g.human.poll()
+	g.adjustPlaySpeed()
+
+	if rv.over {
+		if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+			g.newRaceRound()
+		}
+		return nil
+	}
+
+	if g.frameCount%g.playSpeed != 0 {
+		return nil
+	}
+
+	drivers := [2]policy.Policy{g.human, rv.ai.policy}
+	for i, board := range rv.boards {
+		if !rv.done[i] {
+			_, rv.done[i] = board.Step(drivers[i].SelectAction(board))
+		}
+	}
+
+	if w, decided := rv.result(); decided {
+		rv.over = true
+		rv.rounds++
+		rv.winner = w
+		if w >= 0 {
+			rv.wins[w]++
+			fmt.Printf("🏆 Race %d: %s wins %d:%d\n", rv.rounds, rv.name(w),
+				rv.boards[w].Score(), rv.boards[1-w].Score())
+		} else {
+			fmt.Printf("🤝 Race %d: tie at %d\n", rv.rounds, rv.boards[raceHuman].Score())
+		}
+	}
+	return nil
+}
+
+// result returns winning board once the race is decided: both players
+// are out, or one is out and the other already has a higher score.
+// Equal scores go to the player who survived more steps.
+func (rv *raceView) result() (int, bool) {
+	h, a := rv.boards[raceHuman], rv.boards[raceAI]
+	switch {
+	case rv.done[raceHuman] && !rv.done[raceAI]:
+		return raceAI, a.Score() > h.Score()
+	case rv.done[raceAI] && !rv.done[raceHuman]:
+		return raceHuman, h.Score() > a.Score()
+	case !rv.done[raceHuman]:
+		return -1, false
+	}
+
+	switch {
+	case h.Score() != a.Score():
+		if h.Score() > a.Score() {
+			return raceHuman, true
+		}
+		return raceAI, true
+	case h.Steps() != a.Steps():
+		if h.Steps() > a.Steps() {
+			return raceHuman, true
+		}
+		return raceAI, true
+	}
+	return -1, true
+}
+
+func (rv *raceView) name(board int) string {
+	if board == raceHuman {
+		return "Human"
+	}
+	return "AI (" + rv.ai.name + ")"
+}
+
+func (g *Game) drawRace(screen *ebiten.Image) {
+	rv := g.race
+	viewports := g.renderer.Screen().Split(len(rv.boards), config.RaceGap)
+
+	for i, board := range rv.boards {
+		vp := viewports[i]
+		g.renderer.DrawSnake(screen, board, vp)
+
+		status := "racing"
+		if rv.done[i] {
+			status = "out"
+		}
+		line := fmt.Sprintf("%s | Score: %d | Wins: %d | %s", rv.name(i), board.Score(), rv.wins[i], status)
+		vector.FillRect(screen, float32(vp.X-5), 10, float32(len(line)*6+10), 25, config.ColorTextBg, false)
+		ebitenutil.DebugPrintAt(screen, line, vp.X, 15)
+	}
+
+	status := fmt.Sprintf("Race %d | Tick: %d frames [-/+] | [ARROWS/WASD] Steer | [ESC] Menu", rv.rounds+1, g.playSpeed)
+	if rv.over {
+		result := "Tie!"
+		if rv.winner >= 0 {
+			result = rv.name(rv.winner) + " wins!"
+		}
+		status = result + " [SPACE] Next race | [ESC] Menu"
+	}
+	x := g.screenWidth/2 - len(status)*3
+	vector.FillRect(screen, float32(x-5), 50, float32(len(status)*6+10), 25, config.ColorTextBg, false)
+	ebitenutil.DebugPrintAt(screen, status, x, 55)
+}
@@ -24,58 +24,112 @@ func NewRenderer(width, height int) *Renderer {
 	}
 }
 
-func (r *Renderer) DrawSnake(screen *ebiten.Image, s *snake.Snake) {
-	gridX := config.GridStartX
-	gridY := config.GridStartY
-	cellSize := config.CellSizeInit
-
-	maxWidth := r.screenWidth - gridX*2
-	maxHeight := r.screenHeight - gridY - config.GridPadding
+// Viewport is the screen area a field is fitted into
+type Viewport struct {
+	X, Y          int
+	Width, Height int
+}
 
-	cellWidth := maxWidth / s.Width()
-	cellHeight := maxHeight / s.Height()
+// Screen returns viewport of the whole screen below the header
+func (r *Renderer) Screen() Viewport {
+	return Viewport{
+		X:      config.GridStartX,
+		Y:      config.GridStartY,
+		Width:  r.screenWidth - config.GridStartX*2,
+		Height: r.screenHeight - config.GridStartY - config.GridPadding,
+	}
+}
 
-	if cellWidth < cellHeight {
-		cellSize = cellWidth
-	} else {
-		cellSize = cellHeight
+// Split divides viewport into n side by side columns separated by gap
+func (v Viewport) Split(n, gap int) []Viewport {
+	width := (v.Width - gap*(n-1)) / n
+	parts := make([]Viewport, n)
+	for i := range parts {
+		parts[i] = Viewport{X: v.X + i*(width+gap), Y: v.Y, Width: width, Height: v.Height}
 	}
+	return parts
+}
 
-	if cellSize < config.CellSizeMin {
-		cellSize = config.CellSizeMin
+// gridLayout is position and cell size of the field on screen
+type gridLayout struct {
+	x, y          int
+	cellSize      int
+	width, height int // in cells
+}
+
+// layout fits field of given size into the viewport, centred horizontally
+func (r *Renderer) layout(vp Viewport, width, height int) gridLayout {
+	cellSize := min(vp.Width/width, vp.Height/height)
+	cellSize = max(cellSize, config.CellSizeMin)
+	cellSize = min(cellSize, config.CellSizeMax)
+
+	return gridLayout{
+		x:        vp.X + (vp.Width-width*cellSize)/2,
+		y:        vp.Y,
+		cellSize: cellSize,
+		width:    width,
+		height:   height,
 	}
-	if cellSize > config.CellSizeMax {
-		cellSize = config.CellSizeMax
+}
+
+// cellPos returns screen position of the top-left corner of a cell
+func (l gridLayout) cellPos(p snake.Point) (float32, float32) {
+	return float32(l.x + p.X*l.cellSize), float32(l.y + p.Y*l.cellSize)
+}
+
+// cellAt returns cell under screen position
+func (l gridLayout) cellAt(x, y int) (snake.Point, bool) {
+	if x < l.x || y < l.y {
+		return snake.Point{}, false
 	}
+	p := snake.Point{X: (x - l.x) / l.cellSize, Y: (y - l.y) / l.cellSize}
+	return p, p.X < l.width && p.Y < l.height
+}
 
-	totalWidth := s.Width() * cellSize
-	totalHeight := s.Height() * cellSize
-	gridX = (r.screenWidth - totalWidth) / 2
-
-	for y := 0; y < s.Height(); y++ {
-		for x := 0; x < s.Width(); x++ {
-			posX := float32(gridX + x*cellSize)
-			posY := float32(gridY + y*cellSize)
-			vector.StrokeRect(screen, posX, posY, float32(cellSize), float32(cellSize), 1, config.ColorGrid, false)
+// drawGrid draws empty field cells
+func (r *Renderer) drawGrid(screen *ebiten.Image, l gridLayout) {
+	size := float32(l.cellSize)
+	for y := 0; y < l.height; y++ {
+		for x := 0; x < l.width; x++ {
+			posX, posY := l.cellPos(snake.Point{X: x, Y: y})
+			vector.StrokeRect(screen, posX, posY, size, size, 1, config.ColorGrid, false)
 		}
 	}
+}
+
+// drawObstacle draws wall cell
+func (r *Renderer) drawObstacle(screen *ebiten.Image, l gridLayout, p snake.Point) {
+	posX, posY := l.cellPos(p)
+	size := float32(l.cellSize)
+	vector.FillRect(screen, posX, posY, size, size, config.ColorObstacle, false)
+	vector.StrokeRect(screen, posX, posY, size, size, 2, config.ColorObstacleBorder, false)
+}
+
+// drawFood draws food cell
+func (r *Renderer) drawFood(screen *ebiten.Image, l gridLayout, p snake.Point) {
+	posX, posY := l.cellPos(p)
+	size := float32(l.cellSize)
+	vector.FillRect(screen, posX, posY, size, size, config.ColorFood, false)
+	vector.StrokeRect(screen, posX, posY, size, size, 2, config.ColorFoodBorder, false)
+}
+
+// DrawSnake draws single-snake field inside viewport
+func (r *Renderer) DrawSnake(screen *ebiten.Image, s *snake.Snake, vp Viewport) {
+	l := r.layout(vp, s.Width(), s.Height())
+	gridX, gridY := l.x, l.y
+	cellSize := l.cellSize
+	totalWidth := s.Width() * cellSize
+
+	r.drawGrid(screen, l)
 
 	for _, obs := range s.Obstacles() {
-		posX := float32(gridX + obs.X*cellSize)
-		posY := float32(gridY + obs.Y*cellSize)
-		vector.FillRect(screen, posX, posY, float32(cellSize), float32(cellSize), config.ColorObstacle, false)
-		vector.StrokeRect(screen, posX, posY, float32(cellSize), float32(cellSize), 2, config.ColorObstacleBorder, false)
+		r.drawObstacle(screen, l, obs)
 	}
 
-	food := s.Food()
-	posX := float32(gridX + food.X*cellSize)
-	posY := float32(gridY + food.Y*cellSize)
-	vector.FillRect(screen, posX, posY, float32(cellSize), float32(cellSize), config.ColorFood, false)
-	vector.StrokeRect(screen, posX, posY, float32(cellSize), float32(cellSize), 2, config.ColorFoodBorder, false)
+	r.drawFood(screen, l, s.Food())
 
 	for i, segment := range s.Body() {
-		posX := float32(gridX + segment.X*cellSize)
-		posY := float32(gridY + segment.Y*cellSize)
+		posX, posY := l.cellPos(segment)
 
 		var col color.RGBA
 		if i == 0 {
@@ -99,11 +153,80 @@ func (r *Renderer) DrawSnake(screen *ebiten.Image, s *snake.Snake) {
 	infoText := fmt.Sprintf("Length: %d | Steps: %d | Map: %dx%d | Occupancy: %.1f%% | Obstacles: %d",
 		s.Length(), s.Steps(), s.Width(), s.Height(), occupancy, len(s.Obstacles()))
 
-	_ = totalHeight
 	vector.FillRect(screen, float32(gridX-5), float32(gridY-40), float32(totalWidth+10), 35, config.ColorTextBg, false)
 	ebitenutil.DebugPrintAt(screen, infoText, gridX, gridY-35)
 }
 
+// arenaColor returns head colour of arena snake i
+func arenaColor(i int) color.RGBA {
+	return config.ColorArenaSnakes[i%len(config.ColorArenaSnakes)]
+}
+
+// DrawArena draws shared field with every living snake in its own colour
+func (r *Renderer) DrawArena(screen *ebiten.Image, a *snake.Arena) {
+	l := r.layout(r.Screen(), a.Width(), a.Height())
+	r.drawGrid(screen, l)
+
+	for _, wall := range a.Walls() {
+		r.drawObstacle(screen, l, wall)
+	}
+	for _, food := range a.Food() {
+		r.drawFood(screen, l, food)
+	}
+
+	size := float32(l.cellSize)
+	for i := 0; i < a.SnakeCount(); i++ {
+		if !a.Alive(i) {
+			continue
+		}
+		head := arenaColor(i)
+		body := color.RGBA{head.R / 2, head.G / 2, head.B / 2, 255}
+		for j, segment := range a.Snake(i).Body() {
+			posX, posY := l.cellPos(segment)
+			col := body
+			if j == 0 {
+				col = head
+			}
+			vector.FillRect(screen, posX, posY, size, size, col, false)
+		}
+		posX, posY := l.cellPos(a.Snake(i).Body()[0])
+		vector.StrokeRect(screen, posX, posY, size, size, 2, config.ColorBackground, false)
+	}
+
+	infoText := fmt.Sprintf("Arena: %dx%d | Snakes alive: %d/%d | Food: %d",
+		a.Width(), a.Height(), a.AliveCount(), a.SnakeCount(), len(a.Food()))
+	vector.FillRect(screen, float32(l.x-5), float32(l.y-40), float32(a.Width()*l.cellSize+10), 35, config.ColorTextBg, false)
+	ebitenutil.DebugPrintAt(screen, infoText, l.x, l.y-35)
+}
+
+// DrawLevel draws level layout: walls, numbered food points and spawn
+// with its initial direction
+func (r *Renderer) DrawLevel(screen *ebiten.Image, lvl *snake.Level) gridLayout {
+	l := r.layout(r.Screen(), lvl.Width, lvl.Height)
+	r.drawGrid(screen, l)
+
+	for _, wall := range lvl.Walls {
+		r.drawObstacle(screen, l, wall)
+	}
+
+	for i, food := range lvl.Food {
+		r.drawFood(screen, l, food)
+		posX, posY := l.cellPos(food)
+		ebitenutil.DebugPrintAt(screen, fmt.Sprint(i+1), int(posX)+2, int(posY))
+	}
+
+	posX, posY := l.cellPos(lvl.Spawn)
+	size := float32(l.cellSize)
+	vector.FillRect(screen, posX, posY, size, size, config.ColorSnakeHead, false)
+	vector.StrokeRect(screen, posX, posY, size, size, 2, config.ColorSnakeHeadBorder, false)
+
+	dir := lvl.Direction.ToVector()
+	centerX, centerY := posX+size/2, posY+size/2
+	vector.StrokeLine(screen, centerX, centerY, centerX+float32(dir.X)*size/2, centerY+float32(dir.Y)*size/2, 3, config.ColorBackground, false)
+
+	return l
+}
+
 func (r *Renderer) DrawProgressBar(screen *ebiten.Image, progress float64, current, total int) {
 	barX := float32(10)
 	barY := float32(r.screenHeight - config.ProgressBarMargin)
@@ -0,0 +1,238 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/ebitenutil"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/hajimehoshi/ebiten/v2/vector"
+)
+
+// replayView holds playback state of a recorded episode
+type replayView struct {
+	filename  string
+	episode   *snake.Episode
+	replayer  *snake.Replayer
+	paused    bool
+	speed     int // steps per second
+	stepAccum int
+	jumpInput string
+	err       error
+}
+
+// StartReplay loads recorded episode and switches to replay mode
+func (g *Game) StartReplay(filename string) error {
+	ep, err := snake.LoadEpisode(filename)
+	if err != nil {
+		return err
+	}
+
+	replayer, err := snake.NewReplayer(ep)
+	if err != nil {
+		return err
+	}
+
+	g.replay = &replayView{
+		filename: filename,
+		episode:  ep,
+		replayer: replayer,
+		paused:   true,
+		speed:    config.ReplayStepsPerSecond,
+	}
+	g.state = StateReplay
+	return nil
+}
+
+// latestEpisode returns most recently written episode file
+func latestEpisode() (string, error) {
+	files, err := filepath.Glob(filepath.Join(config.EpisodeDir, "*"+config.EpisodeExt))
+	if err != nil {
+		return "", err
+	}
+
+	latest := ""
+	var latestInfo os.FileInfo
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			continue
+		}
+		if latestInfo == nil || info.ModTime().After(latestInfo.ModTime()) {
+			latest, latestInfo = f, info
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no recorded episodes in %s", config.EpisodeDir)
+	}
+	return latest, nil
+}
+
+func (g *Game) startLatestReplay() {
+	filename, err := latestEpisode()
+	if err == nil {
+		err = g.StartReplay(filename)
+	}
+	if err != nil {
+		fmt.Printf("⚠️ Cannot start replay: %v\n", err)
+	}
+}
+
+// seek rebuilds replay at given step by re-stepping from the start
+func (rv *replayView) seek(step int) {
+	if step < 0 {
+		step = 0
+	}
+	if step > rv.episode.Len() {
+		step = rv.episode.Len()
+	}
+
+	replayer, err := snake.NewReplayer(rv.episode)
+	if err != nil {
+		rv.err = err
+		return
+	}
+	for replayer.Position() < step && !replayer.Done() {
+		if err := replayer.Step(); err != nil {
+			rv.err = err
+			break
+		}
+	}
+
+	rv.replayer = replayer
+	rv.stepAccum = 0
+}
+
+// stepForward applies next recorded action
+func (rv *replayView) stepForward() {
+	if rv.replayer.Done() {
+		rv.paused = true
+		return
+	}
+	if err := rv.replayer.Step(); err != nil {
+		rv.err = err
+		rv.paused = true
+	}
+}
+
+func (g *Game) updateReplay() error {
+	rv := g.replay
+
+	if inpututil.IsKeyJustPressed(ebiten.KeyEscape) {
+		g.replay = nil
+		g.state = StateMenu
+		return nil
+	}
+
+	if inpututil.IsKeyJustPressed(ebiten.KeySpace) {
+		rv.paused = !rv.paused
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyRight) {
+		rv.paused = true
+		rv.stepForward()
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyLeft) {
+		rv.paused = true
+		rv.seek(rv.replayer.Position() - 1)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyUp) && rv.speed < config.ReplayMaxSpeed {
+		rv.speed *= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyDown) && rv.speed > 1 {
+		rv.speed /= 2
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyHome) {
+		rv.seek(0)
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnd) {
+		rv.seek(rv.episode.Len())
+	}
+
+	// Переход к шагу: цифры + ENTER
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r >= '0' && r <= '9' && len(rv.jumpInput) < 7 {
+			rv.jumpInput += string(r)
+		}
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyBackspace) && len(rv.jumpInput) > 0 {
+		rv.jumpInput = rv.jumpInput[:len(rv.jumpInput)-1]
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyEnter) && rv.jumpInput != "" {
+		if step, err := strconv.Atoi(rv.jumpInput); err == nil {
+			rv.paused = true
+			rv.seek(step)
+		}
+		rv.jumpInput = ""
+	}
+
+	if rv.paused {
+		return nil
+	}
+
+	// speed шагов в секунду при ebiten.TPS() обновлениях в секунду
+	rv.stepAccum += rv.speed
+	for rv.stepAccum >= ebiten.TPS() && !rv.paused {
+		rv.stepAccum -= ebiten.TPS()
+		rv.stepForward()
+	}
+
+	return nil
+}
+
+func (g *Game) drawReplay(screen *ebiten.Image) {
+	rv := g.replay
+	s := rv.replayer.Snake()
+	g.renderer.DrawSnake(screen, s, g.renderer.Screen())
+
+	status := "PLAYING"
+	if rv.paused {
+		status = "PAUSED"
+	}
+	if rv.replayer.Done() {
+		status = "END"
+		if final, ok := rv.episode.Final(); ok {
+			status = fmt.Sprintf("END (score %d, length %d)", final.Score, final.Length)
+		}
+	}
+
+	infoText := fmt.Sprintf("REPLAY %s\nStep: %d/%d | Score: %d | Speed: %d steps/s | %s",
+		filepath.Base(rv.filename), rv.replayer.Position(), rv.replayer.Len(), s.Score(), rv.speed, status)
+	if rv.jumpInput != "" {
+		infoText += fmt.Sprintf(" | Jump to: %s_", rv.jumpInput)
+	}
+	if rv.err != nil {
+		infoText += fmt.Sprintf("\n%v", rv.err)
+	}
+
+	vector.FillRect(screen, 10, 10, float32(config.StatsBoxWidth), float32(config.StatsBoxHeight), config.ColorTextBg, false)
+	ebitenutil.DebugPrintAt(screen, infoText, 15, 15)
+
+	g.drawReplayTimeline(screen, rv)
+}
+
+// drawReplayTimeline draws playback position bar with controls hint
+func (g *Game) drawReplayTimeline(screen *ebiten.Image, rv *replayView) {
+	barX := float32(10)
+	barY := float32(g.screenHeight - config.ProgressBarMargin)
+	barWidth := float32(g.screenWidth - 20)
+	barHeight := float32(config.ProgressBarHeight)
+
+	vector.FillRect(screen, barX, barY, barWidth, barHeight, config.ColorProgressBg, false)
+	vector.StrokeRect(screen, barX, barY, barWidth, barHeight, 2, config.ColorProgressBorder, false)
+
+	if rv.replayer.Len() > 0 {
+		progress := float32(rv.replayer.Position()) / float32(rv.replayer.Len())
+		if fillWidth := barWidth * progress; fillWidth > 4 {
+			vector.FillRect(screen, barX+2, barY+2, fillWidth-4, barHeight-4, config.ColorSnakeHeadBorder, false)
+		}
+	}
+
+	ebitenutil.DebugPrintAt(screen, config.ReplayControls, int(barX)+10, int(barY)+10)
+}
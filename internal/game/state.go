@@ -8,4 +8,8 @@ const (
 	StateTraining
 	StatePlaying
 	StateGameOver
+	StateReplay
+	StateEditor
+	StateArena
+	StateRace
 )
@@ -0,0 +1,124 @@
+// Package imitation turns recorded episodes (human play or scripted
+// policies) into demonstration transitions for pretraining the agent
+package imitation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+)
+
+// FromEpisode replays a recorded episode and returns its transitions
+func FromEpisode(ep *snake.Episode) ([]ai.Experience, error) {
+	r, err := snake.NewReplayer(ep)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := ep.Actions()
+	demos := make([]ai.Experience, 0, len(actions))
+	for !r.Done() {
+		s := r.Snake()
+		state := s.GetState()
+		action := actions[r.Position()]
+		if err := r.Step(); err != nil {
+			return nil, err
+		}
+
+		demos = append(demos, ai.Experience{
+			State:     state,
+			Action:    action,
+			Reward:    r.Reward(),
+			NextState: s.GetState(),
+			NextMask:  s.LegalActions(),
+			Done:      r.Done(),
+		})
+	}
+	return demos, nil
+}
+
+// LoadDir converts every episode in dir whose file name starts with one
+// of prefixes (all episodes when none given). Episodes recorded with other
+// observation features or action mode than the current config are skipped.
+func LoadDir(dir string, prefixes []string) ([]ai.Experience, int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+config.EpisodeExt))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var demos []ai.Experience
+	episodes := 0
+	for _, file := range files {
+		if !hasPrefix(filepath.Base(file), prefixes) {
+			continue
+		}
+
+		ep, err := snake.LoadEpisode(file)
+		if err != nil {
+			fmt.Printf("⚠️ Skipping %s: %v\n", file, err)
+			continue
+		}
+		if ep.Header.Features != snake.DefaultFeatures() || ep.Header.ActionMode != snake.DefaultActionMode() {
+			fmt.Printf("⚠️ Skipping %s: recorded with other features or action mode\n", file)
+			continue
+		}
+
+		exps, err := FromEpisode(ep)
+		if err != nil {
+			fmt.Printf("⚠️ Skipping %s: %v\n", file, err)
+			continue
+		}
+		demos = append(demos, exps...)
+		episodes++
+	}
+	return demos, episodes, nil
+}
+
+func hasPrefix(name string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if p = strings.TrimSpace(p); p != "" && strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record plays episodes with a scripted policy and saves them to dir as
+// demonstrations named <DemoPrefix>_<name>_<seed>. Episode i uses seed
+// opts.Seed+i (1+i when unset).
+func Record(p policy.Policy, name string, opts snake.Options, episodes int, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create demo directory: %w", err)
+	}
+
+	baseSeed := opts.Seed
+	if baseSeed == 0 {
+		baseSeed = 1
+	}
+
+	for i := 0; i < episodes; i++ {
+		opts.Seed = baseSeed + uint64(i)
+		s := snake.NewSnakeWithOptions(opts)
+		rec := s.StartRecording()
+		for {
+			if _, done := s.Step(p.SelectAction(s)); done {
+				break
+			}
+		}
+
+		filename := filepath.Join(dir, fmt.Sprintf("%s_%s_%d%s", config.DemoPrefix, name, opts.Seed, config.EpisodeExt))
+		if err := rec.Save(filename); err != nil {
+			return fmt.Errorf("save demo: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+// Package league keeps a pool of frozen snapshots of the learning network
+// that serve as self-play opponents, with Elo ratings and win rates
+package league
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"math/rand/v2"
+	"os"
+	"sort"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/atomicfile"
+)
+
+// Member is a frozen opponent of the league
+type Member struct {
+	ID      int     `json:"id"`
+	File    string  `json:"file"`
+	Episode int     `json:"episode"` // Learner episode when frozen
+	Elo     float64 `json:"elo"`
+	Games   int     `json:"games"`
+	Wins    int     `json:"wins"` // Games the member won against the learner
+	Losses  int     `json:"losses"`
+	Draws   int     `json:"draws"`
+
+	net *ai.Network
+}
+
+// Network returns frozen network of the member
+func (m *Member) Network() *ai.Network { return m.net }
+
+// LearnerWinRate returns share of games the learner won against the
+// member, counting draws as half; 0.5 before any game
+func (m *Member) LearnerWinRate() float64 {
+	if m.Games == 0 {
+		return 0.5
+	}
+	return (float64(m.Losses) + 0.5*float64(m.Draws)) / float64(m.Games)
+}
+
+// League is the opponent pool together with the learner's rating
+type League struct {
+	Members    []*Member `json:"members"`
+	LearnerElo float64   `json:"learner_elo"`
+	NextID     int       `json:"next_id"`
+
+	filename string
+}
+
+// New creates empty league stored in filename
+func New(filename string) *League {
+	return &League{LearnerElo: config.LeagueInitialElo, NextID: 1, filename: filename}
+}
+
+// Load reads league index and networks of its members
+func Load(filename string) (*League, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read league: %w", err)
+	}
+
+	l := &League{filename: filename}
+	if err := json.Unmarshal(data, l); err != nil {
+		return nil, fmt.Errorf("parse league %s: %w", filename, err)
+	}
+
+	for _, m := range l.Members {
+		m.net, err = ai.LoadNetworkFor(m.File, config.GetStateSize(), config.GetActionSize(), config.ActionMode)
+		if err != nil {
+			return nil, fmt.Errorf("load league member %d: %w", m.ID, err)
+		}
+	}
+	return l, nil
+}
+
+// Save writes league index; member networks are written when frozen
+func (l *League) Save() error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal league: %w", err)
+	}
+	return atomicfile.WriteFile(l.filename, data, 0644)
+}
+
+// Freeze adds a copy of net to the pool, rated as the learner is now.
+// When the pool is full the lowest rated member is dropped; a member
+// whose file cannot be deleted stays in the pool, so it is not orphaned.
+func (l *League) Freeze(net *ai.Network, episode int) (*Member, error) {
+	m := &Member{
+		ID:      l.NextID,
+		File:    fmt.Sprintf("%s%d%s", config.LeaguePrefix, l.NextID, config.ModelGenExt),
+		Episode: episode,
+		Elo:     l.LearnerElo,
+		net:     net.Clone(),
+	}
+	if err := m.net.SaveToFile(m.File); err != nil {
+		return nil, fmt.Errorf("save league member: %w", err)
+	}
+
+	l.NextID++
+	l.Members = append(l.Members, m)
+
+	if len(l.Members) > config.LeagueMaxSize {
+		weakest := 0
+		for i, other := range l.Members[:len(l.Members)-1] {
+			if other.Elo < l.Members[weakest].Elo {
+				weakest = i
+			}
+		}
+		if err := os.Remove(l.Members[weakest].File); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			fmt.Printf("⚠️ Failed to delete league member #%d: %v\n", l.Members[weakest].ID, err)
+		} else {
+			l.Members = append(l.Members[:weakest], l.Members[weakest+1:]...)
+		}
+	}
+
+	return m, nil
+}
+
+// Sample picks n opponents with prioritized fictitious self-play:
+// members the learner struggles against are chosen more often
+func (l *League) Sample(rng *rand.Rand, n int) []*Member {
+	if len(l.Members) == 0 {
+		return nil
+	}
+
+	weights := make([]float64, len(l.Members))
+	total := 0.0
+	for i, m := range l.Members {
+		loss := 1 - m.LearnerWinRate()
+		weights[i] = loss*loss + config.LeagueMinWeight
+		total += weights[i]
+	}
+
+	picked := make([]*Member, n)
+	for k := range picked {
+		r := rng.Float64() * total
+		i := 0
+		for ; i < len(weights)-1 && r >= weights[i]; i++ {
+			r -= weights[i]
+		}
+		picked[k] = l.Members[i]
+	}
+	return picked
+}
+
+// Report records a game between learner and member. Outcome is 1 when
+// the learner won, 0 for a loss and 0.5 for a draw.
+func (l *League) Report(m *Member, outcome float64) {
+	expected := 1 / (1 + math.Pow(10, (m.Elo-l.LearnerElo)/400))
+	delta := config.LeagueEloK * (outcome - expected)
+	l.LearnerElo += delta
+	m.Elo -= delta
+
+	m.Games++
+	switch outcome {
+	case 1:
+		m.Losses++
+	case 0:
+		m.Wins++
+	default:
+		m.Draws++
+	}
+}
+
+// Standings returns members sorted by rating, strongest first
+func (l *League) Standings() []*Member {
+	sorted := append([]*Member(nil), l.Members...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Elo > sorted[j].Elo })
+	return sorted
+}
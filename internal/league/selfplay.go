@@ -0,0 +1,128 @@
+package league
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/policy"
+	"snakes-ml/internal/snake"
+)
+
+// RoundResult describes one self-play round from the learner's side
+type RoundResult struct {
+	Score     int
+	Steps     int
+	Opponents []*Member // nil entries are scripted opponents
+	Outcomes  []float64 // Learner result against each opponent: 1, 0.5 or 0
+}
+
+// Trainer plays the learning agent (snake 0) against league members
+// frozen from its own earlier versions
+type Trainer struct {
+	agent    *ai.Agent
+	league   *League
+	opts     snake.ArenaOptions
+	rng      *rand.Rand
+	scripted policy.Policy // Opponent while the league is empty
+	stop     atomic.Bool
+}
+
+// NewTrainer creates self-play trainer. Arena options set the field;
+// opts.Snakes is the learner plus its opponents.
+func NewTrainer(agent *ai.Agent, league *League, opts snake.ArenaOptions, seed uint64) *Trainer {
+	return &Trainer{
+		agent:    agent,
+		league:   league,
+		opts:     opts,
+		rng:      rand.New(rand.NewPCG(seed, seed^0x5e1f)),
+		scripted: policy.NewAStar(),
+	}
+}
+
+// Stop makes the running round return early; safe to call from other
+// goroutines, e.g. a signal handler
+func (t *Trainer) Stop() {
+	t.stop.Store(true)
+}
+
+// PlayRound plays one round, trains the learner on its transitions and
+// updates ratings of the sampled opponents. Every LeagueFreezeEvery
+// learner episodes the learner is frozen into the league. Returns false
+// if Stop interrupted the round; it then counts for nobody.
+func (t *Trainer) PlayRound() (RoundResult, bool) {
+	opponents := t.league.Sample(t.rng, t.opts.Snakes-1)
+	policies := make([]policy.Policy, t.opts.Snakes)
+	for i := 1; i < len(policies); i++ {
+		if opponents == nil {
+			policies[i] = t.scripted
+			continue
+		}
+		policies[i] = policy.NewGreedy(opponents[i-1].Network())
+	}
+
+	opts := t.opts
+	opts.Seed = t.rng.Uint64()
+	a := snake.NewArena(opts)
+
+	for {
+		actions := make([]int, a.SnakeCount())
+		learnerAlive := a.Alive(0)
+		var state []float64
+		if learnerAlive {
+			state = a.State(0)
+			actions[0] = t.agent.SelectAction(state, a.LegalActions(0))
+		}
+		for i := 1; i < len(actions); i++ {
+			if a.Alive(i) {
+				actions[i] = policies[i].SelectAction(a.Snake(i))
+			}
+		}
+
+		rewards, done := a.Step(actions)
+
+		if learnerAlive {
+			learnerDone := done || !a.Alive(0)
+			t.agent.Remember(state, actions[0], rewards[0], a.State(0), learnerDone, a.LegalActions(0))
+			if t.agent.ReplayBufferSize() >= config.MinBufferSize {
+				t.agent.Train()
+			}
+		}
+
+		if done {
+			break
+		}
+		if t.stop.Load() {
+			return RoundResult{}, false
+		}
+	}
+	t.agent.EndEpisode()
+
+	res := RoundResult{Score: a.Score(0), Steps: a.Steps()}
+	if opponents == nil {
+		res.Opponents = make([]*Member, len(policies)-1)
+	} else {
+		res.Opponents = opponents
+	}
+	for i, m := range res.Opponents {
+		outcome := float64(a.Compare(0, i+1)+1) / 2
+		res.Outcomes = append(res.Outcomes, outcome)
+		if m != nil {
+			t.league.Report(m, outcome)
+		}
+	}
+
+	if t.agent.EpisodeCount()%config.LeagueFreezeEvery == 0 {
+		m, err := t.league.Freeze(t.agent.Network(), t.agent.EpisodeCount())
+		if err != nil {
+			fmt.Printf("⚠️ League freeze failed: %v\n", err)
+		} else {
+			fmt.Printf("🧊 Frozen league member #%d (Elo %.0f, pool %d)\n",
+				m.ID, m.Elo, len(t.league.Members))
+		}
+	}
+
+	return res, true
+}
@@ -0,0 +1,208 @@
+// Package onnx exports ai.Network to ONNX with a self-contained protobuf
+// encoder and runs exported graphs with a small interpreter to check them
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/atomicfile"
+)
+
+// Exported graph interface. The batch dimension is symbolic.
+const (
+	InputName  = "state"
+	OutputName = "q_values"
+	BatchDim   = "batch"
+
+	opsetVersion = 13
+	irVersion    = 7 // Matches opset 13
+	producerName = "snakes-ml"
+)
+
+// ONNX enum values
+const (
+	tensorFloat = 1 // TensorProto.FLOAT
+
+	attrFloat = 1 // AttributeProto.FLOAT
+	attrInt   = 2 // AttributeProto.INT
+)
+
+// activationOps maps activations of ModelInfo to ONNX operators; an empty
+// operator means the layer output is used as is. New layer activations
+// are exported by adding them here and to the interpreter.
+var activationOps = map[string]string{
+	ai.ActivationReLU:   "Relu",
+	ai.ActivationLinear: "",
+}
+
+// node is a graph node under construction
+type node struct {
+	op      string
+	inputs  []string
+	outputs []string
+	ints    map[string]int64
+	floats  map[string]float32
+}
+
+// tensor is an initializer under construction, float32 row-major
+type tensor struct {
+	name string
+	dims []int64
+	data []float32
+}
+
+// Export encodes net as an ONNX model: every dense layer becomes Gemm
+// followed by its activation operator
+func Export(net *ai.Network) ([]byte, error) {
+	layers := net.Layers()
+	info := net.Info()
+
+	var nodes []node
+	var inits []tensor
+	current := InputName
+	for i := 0; i+1 < len(layers); i++ {
+		act := info.Activation
+		if i == len(layers)-2 {
+			act = info.Output
+		}
+		op, ok := activationOps[act]
+		if !ok {
+			return nil, fmt.Errorf("layer %d: activation %q has no ONNX operator", i, act)
+		}
+
+		weights, biases := net.LayerParams(i)
+		w := tensor{name: fmt.Sprintf("dense%d.weight", i), dims: []int64{int64(layers[i]), int64(layers[i+1])}}
+		for _, row := range weights {
+			for _, v := range row {
+				w.data = append(w.data, float32(v))
+			}
+		}
+		b := tensor{name: fmt.Sprintf("dense%d.bias", i), dims: []int64{int64(layers[i+1])}}
+		for _, v := range biases {
+			b.data = append(b.data, float32(v))
+		}
+		inits = append(inits, w, b)
+
+		out := fmt.Sprintf("dense%d", i)
+		if op == "" && i == len(layers)-2 {
+			out = OutputName
+		}
+		nodes = append(nodes, node{op: "Gemm", inputs: []string{current, w.name, b.name}, outputs: []string{out},
+			floats: map[string]float32{"alpha": 1, "beta": 1}, ints: map[string]int64{"transA": 0, "transB": 0}})
+		current = out
+
+		if op != "" {
+			out = fmt.Sprintf("%s%d", strings.ToLower(op), i)
+			if i == len(layers)-2 {
+				out = OutputName
+			}
+			nodes = append(nodes, node{op: op, inputs: []string{current}, outputs: []string{out}})
+			current = out
+		}
+	}
+
+	meta := net.AllMetadata()
+	if info.Encoder != "" {
+		meta["encoder"] = info.Encoder
+	}
+	if info.ActionMode != "" {
+		meta[ai.MetaActionMode] = info.ActionMode
+	}
+
+	var m protoWriter
+	m.varint(1, irVersion)
+	m.string(2, producerName)
+	m.message(7, func(g *protoWriter) {
+		for i, n := range nodes {
+			g.message(1, func(w *protoWriter) { writeNode(w, n, i) })
+		}
+		g.string(2, "snake_q_network")
+		for _, t := range inits {
+			g.message(5, func(w *protoWriter) { writeTensor(w, t) })
+		}
+		g.message(11, func(w *protoWriter) { writeValueInfo(w, InputName, layers[0]) })
+		g.message(12, func(w *protoWriter) { writeValueInfo(w, OutputName, layers[len(layers)-1]) })
+	})
+	m.message(8, func(w *protoWriter) {
+		w.string(1, "")
+		w.varint(2, opsetVersion)
+	})
+	for _, k := range sortedKeys(meta) {
+		m.message(14, func(w *protoWriter) {
+			w.string(1, k)
+			w.string(2, meta[k])
+		})
+	}
+	return m.buf, nil
+}
+
+// ExportFile writes net to filename as an ONNX model
+func ExportFile(net *ai.Network, filename string) error {
+	data, err := Export(net)
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(filename, data, 0644)
+}
+
+func writeNode(w *protoWriter, n node, index int) {
+	for _, in := range n.inputs {
+		w.string(1, in)
+	}
+	for _, out := range n.outputs {
+		w.string(2, out)
+	}
+	w.string(3, fmt.Sprintf("%s_%d", n.op, index))
+	w.string(4, n.op)
+	for _, name := range sortedKeys(n.floats) {
+		w.message(5, func(a *protoWriter) {
+			a.string(1, name)
+			a.float32(2, n.floats[name])
+			a.varint(20, attrFloat)
+		})
+	}
+	for _, name := range sortedKeys(n.ints) {
+		w.message(5, func(a *protoWriter) {
+			a.string(1, name)
+			a.varint(3, n.ints[name])
+			a.varint(20, attrInt)
+		})
+	}
+}
+
+func writeTensor(w *protoWriter, t tensor) {
+	for _, d := range t.dims {
+		w.varint(1, d)
+	}
+	w.varint(2, tensorFloat)
+	w.string(8, t.name)
+	raw := make([]byte, 0, 4*len(t.data))
+	for _, v := range t.data {
+		raw = binary.LittleEndian.AppendUint32(raw, math.Float32bits(v))
+	}
+	w.bytes(9, raw)
+}
+
+// writeValueInfo declares a float tensor of shape [batch, size]
+func writeValueInfo(w *protoWriter, name string, size int) {
+	w.string(1, name)
+	w.message(2, func(t *protoWriter) {
+		t.message(1, func(tt *protoWriter) {
+			tt.varint(1, tensorFloat)
+			tt.message(2, func(s *protoWriter) {
+				s.message(1, func(d *protoWriter) { d.string(2, BatchDim) })
+				s.message(1, func(d *protoWriter) { d.varint(1, int64(size)) })
+			})
+		})
+	})
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	return slices.Sorted(maps.Keys(m))
+}
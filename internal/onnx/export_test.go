@@ -0,0 +1,56 @@
+package onnx
+
+import (
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"snakes-ml/internal/ai"
+)
+
+func TestExportMatchesForward(t *testing.T) {
+	net := ai.NewNetwork([]int{6, 16, 8, 4}, 0.001)
+	data, err := Export(net)
+	if err != nil {
+		t.Fatal(err)
+	}
+	model, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const batch, in = 32, 6
+	rng := rand.New(rand.NewPCG(1, 2))
+	states := &Tensor{Dims: []int{batch, in}, Data: make([]float64, batch*in)}
+	for i := range states.Data {
+		states.Data[i] = rng.Float64()*2 - 1
+	}
+	outs, err := model.Run(map[string]*Tensor{InputName: states})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := outs[OutputName]
+	if got == nil || len(got.Dims) != 2 || got.Dims[0] != batch || got.Dims[1] != 4 {
+		t.Fatalf("output %q has wrong shape: %+v", OutputName, got)
+	}
+
+	maxDiff := 0.0
+	for b := 0; b < batch; b++ {
+		want := net.Forward(states.Data[b*in : (b+1)*in])
+		for k, v := range want {
+			maxDiff = math.Max(maxDiff, math.Abs(v-got.Data[b*4+k]))
+		}
+	}
+	if maxDiff > 1e-5 {
+		t.Errorf("exported graph differs from Forward by %.2e", maxDiff)
+	}
+}
+
+func TestExportUnsupportedActivation(t *testing.T) {
+	net := ai.NewNetwork([]int{6, 8, 4}, 0.001)
+	net.SetInfo(ai.ModelInfo{Activation: "tanh", Output: ai.ActivationLinear})
+	if _, err := Export(net); err == nil || !strings.Contains(err.Error(), "tanh") {
+		t.Fatalf("Export with tanh activation: got error %v, want one naming tanh", err)
+	}
+}
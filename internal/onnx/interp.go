@@ -0,0 +1,403 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Tensor is a dense float tensor, row-major
+type Tensor struct {
+	Dims []int
+	Data []float64
+}
+
+// Node is a decoded graph node
+type Node struct {
+	Op      string
+	Inputs  []string
+	Outputs []string
+	Ints    map[string]int64
+	Floats  map[string]float32
+}
+
+// Model is a decoded ONNX model reduced to what the interpreter runs
+type Model struct {
+	IRVersion    int64
+	Opset        int64
+	Producer     string
+	Inputs       []string
+	Outputs      []string
+	Nodes        []Node
+	Initializers map[string]*Tensor
+	Metadata     map[string]string
+}
+
+// operators implements the ops the interpreter supports
+var operators = map[string]func(n Node, in []*Tensor) (*Tensor, error){
+	"Gemm":     gemm,
+	"MatMul":   matMul,
+	"Add":      add,
+	"Relu":     relu,
+	"Identity": func(_ Node, in []*Tensor) (*Tensor, error) { return in[0], nil },
+}
+
+// Parse decodes an ONNX model. Only float tensors are supported.
+func Parse(data []byte) (*Model, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse model: %w", err)
+	}
+
+	m := &Model{Initializers: make(map[string]*Tensor), Metadata: make(map[string]string)}
+	var graph []byte
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			m.IRVersion = int64(f.val)
+		case 2:
+			m.Producer = string(f.data)
+		case 7:
+			graph = f.data
+		case 8:
+			opset, err := parseProto(f.data)
+			if err != nil {
+				return nil, fmt.Errorf("parse opset: %w", err)
+			}
+			for _, o := range opset {
+				if o.num == 2 {
+					m.Opset = int64(o.val)
+				}
+			}
+		case 14:
+			kv, err := parseStrings(f.data)
+			if err != nil {
+				return nil, fmt.Errorf("parse metadata: %w", err)
+			}
+			m.Metadata[kv[1]] = kv[2]
+		}
+	}
+	if graph == nil {
+		return nil, fmt.Errorf("model has no graph")
+	}
+	if err := m.parseGraph(graph); err != nil {
+		return nil, fmt.Errorf("parse graph: %w", err)
+	}
+	return m, nil
+}
+
+func (m *Model) parseGraph(data []byte) error {
+	fields, err := parseProto(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			n, err := parseNode(f.data)
+			if err != nil {
+				return err
+			}
+			m.Nodes = append(m.Nodes, n)
+		case 5:
+			name, t, err := parseTensor(f.data)
+			if err != nil {
+				return err
+			}
+			m.Initializers[name] = t
+		case 11, 12:
+			s, err := parseStrings(f.data)
+			if err != nil {
+				return err
+			}
+			if f.num == 11 {
+				m.Inputs = append(m.Inputs, s[1])
+			} else {
+				m.Outputs = append(m.Outputs, s[1])
+			}
+		}
+	}
+	return nil
+}
+
+// parseStrings returns the last value of each string field by number
+func parseStrings(data []byte) (map[int]string, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return nil, err
+	}
+	s := make(map[int]string)
+	for _, f := range fields {
+		if f.wire == wireBytes {
+			s[f.num] = string(f.data)
+		}
+	}
+	return s, nil
+}
+
+func parseNode(data []byte) (Node, error) {
+	n := Node{Ints: make(map[string]int64), Floats: make(map[string]float32)}
+	fields, err := parseProto(data)
+	if err != nil {
+		return n, err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			n.Inputs = append(n.Inputs, string(f.data))
+		case 2:
+			n.Outputs = append(n.Outputs, string(f.data))
+		case 4:
+			n.Op = string(f.data)
+		case 5:
+			attr, err := parseProto(f.data)
+			if err != nil {
+				return n, err
+			}
+			var name string
+			var kind uint64
+			var fv float32
+			var iv int64
+			for _, a := range attr {
+				switch a.num {
+				case 1:
+					name = string(a.data)
+				case 2:
+					fv = math.Float32frombits(uint32(a.val))
+				case 3:
+					iv = int64(a.val)
+				case 20:
+					kind = a.val
+				}
+			}
+			switch kind {
+			case attrFloat:
+				n.Floats[name] = fv
+			case attrInt:
+				n.Ints[name] = iv
+			default:
+				return n, fmt.Errorf("node %s: attribute %s has unsupported type %d", n.Op, name, kind)
+			}
+		}
+	}
+	return n, nil
+}
+
+func parseTensor(data []byte) (string, *Tensor, error) {
+	fields, err := parseProto(data)
+	if err != nil {
+		return "", nil, err
+	}
+	var name string
+	t := &Tensor{}
+	for _, f := range fields {
+		switch f.num {
+		case 1:
+			dims, err := f.int64s()
+			if err != nil {
+				return "", nil, err
+			}
+			for _, d := range dims {
+				t.Dims = append(t.Dims, int(d))
+			}
+		case 2:
+			if f.val != tensorFloat {
+				return "", nil, fmt.Errorf("tensor data type %d is not float", f.val)
+			}
+		case 4:
+			vs, err := f.float32s()
+			if err != nil {
+				return "", nil, err
+			}
+			for _, v := range vs {
+				t.Data = append(t.Data, float64(v))
+			}
+		case 8:
+			name = string(f.data)
+		case 9:
+			if len(f.data)%4 != 0 {
+				return "", nil, fmt.Errorf("raw data of %d bytes", len(f.data))
+			}
+			for i := 0; i < len(f.data); i += 4 {
+				t.Data = append(t.Data, float64(math.Float32frombits(binary.LittleEndian.Uint32(f.data[i:]))))
+			}
+		}
+	}
+	if size := t.size(); size != len(t.Data) {
+		return "", nil, fmt.Errorf("tensor %s has shape %v but %d values", name, t.Dims, len(t.Data))
+	}
+	return name, t, nil
+}
+
+func (t *Tensor) size() int {
+	size := 1
+	for _, d := range t.Dims {
+		size *= d
+	}
+	return size
+}
+
+// Run evaluates the graph on named inputs and returns its outputs
+func (m *Model) Run(inputs map[string]*Tensor) (map[string]*Tensor, error) {
+	values := make(map[string]*Tensor, len(m.Initializers)+len(m.Nodes))
+	for k, v := range m.Initializers {
+		values[k] = v
+	}
+	for _, name := range m.Inputs {
+		if _, ok := values[name]; ok {
+			continue
+		}
+		t, ok := inputs[name]
+		if !ok {
+			return nil, fmt.Errorf("missing input %s", name)
+		}
+		values[name] = t
+	}
+
+	for i, n := range m.Nodes {
+		op, ok := operators[n.Op]
+		if !ok {
+			return nil, fmt.Errorf("node %d: unsupported operator %s", i, n.Op)
+		}
+		if len(n.Inputs) == 0 || len(n.Outputs) == 0 {
+			return nil, fmt.Errorf("node %d (%s) has no inputs or outputs", i, n.Op)
+		}
+		in := make([]*Tensor, len(n.Inputs))
+		for j, name := range n.Inputs {
+			if in[j], ok = values[name]; !ok {
+				return nil, fmt.Errorf("node %d (%s): input %s is not computed yet", i, n.Op, name)
+			}
+		}
+		out, err := op(n, in)
+		if err != nil {
+			return nil, fmt.Errorf("node %d (%s): %w", i, n.Op, err)
+		}
+		values[n.Outputs[0]] = out
+	}
+
+	outputs := make(map[string]*Tensor, len(m.Outputs))
+	for _, name := range m.Outputs {
+		t, ok := values[name]
+		if !ok {
+			return nil, fmt.Errorf("output %s is not computed", name)
+		}
+		outputs[name] = t
+	}
+	return outputs, nil
+}
+
+// at returns element [i, j] of a 2-D tensor, transposed if trans is set
+func (t *Tensor) at(i, j int, trans bool) float64 {
+	if trans {
+		i, j = j, i
+	}
+	return t.Data[i*t.Dims[1]+j]
+}
+
+// broadcastable reports whether t broadcasts to [rows, cols]
+func (t *Tensor) broadcastable(rows, cols int) bool {
+	switch {
+	case len(t.Data) == 1:
+		return true
+	case len(t.Dims) == 1:
+		return t.Dims[0] == cols
+	case len(t.Dims) == 2:
+		return (t.Dims[0] == 1 || t.Dims[0] == rows) && (t.Dims[1] == 1 || t.Dims[1] == cols)
+	}
+	return false
+}
+
+// broadcast returns element [i, j] of t broadcast to [rows, cols]
+func (t *Tensor) broadcast(i, j int) float64 {
+	switch {
+	case len(t.Data) == 1:
+		return t.Data[0]
+	case len(t.Dims) == 1:
+		return t.Data[j]
+	case t.Dims[0] == 1:
+		return t.Data[j]
+	case t.Dims[1] == 1:
+		return t.Data[i]
+	default:
+		return t.Data[i*t.Dims[1]+j]
+	}
+}
+
+// gemm computes alpha*A'*B' + beta*C
+func gemm(n Node, in []*Tensor) (*Tensor, error) {
+	if len(in) < 2 {
+		return nil, fmt.Errorf("needs 2 or 3 inputs, got %d", len(in))
+	}
+	a, b := in[0], in[1]
+	transA, transB := n.Ints["transA"] != 0, n.Ints["transB"] != 0
+	alpha, beta := 1.0, 1.0
+	if v, ok := n.Floats["alpha"]; ok {
+		alpha = float64(v)
+	}
+	if v, ok := n.Floats["beta"]; ok {
+		beta = float64(v)
+	}
+	if len(a.Dims) != 2 || len(b.Dims) != 2 {
+		return nil, fmt.Errorf("inputs must be 2-D, got %v and %v", a.Dims, b.Dims)
+	}
+
+	rows, inner := a.Dims[0], a.Dims[1]
+	if transA {
+		rows, inner = inner, rows
+	}
+	innerB, cols := b.Dims[0], b.Dims[1]
+	if transB {
+		innerB, cols = cols, innerB
+	}
+	if inner != innerB {
+		return nil, fmt.Errorf("shapes %v and %v do not multiply", a.Dims, b.Dims)
+	}
+	if len(in) > 2 && !in[2].broadcastable(rows, cols) {
+		return nil, fmt.Errorf("bias shape %v does not broadcast to [%d %d]", in[2].Dims, rows, cols)
+	}
+
+	out := &Tensor{Dims: []int{rows, cols}, Data: make([]float64, rows*cols)}
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			sum := 0.0
+			for k := 0; k < inner; k++ {
+				sum += a.at(i, k, transA) * b.at(k, j, transB)
+			}
+			out.Data[i*cols+j] = alpha * sum
+			if len(in) > 2 {
+				out.Data[i*cols+j] += beta * in[2].broadcast(i, j)
+			}
+		}
+	}
+	return out, nil
+}
+
+func matMul(_ Node, in []*Tensor) (*Tensor, error) {
+	return gemm(Node{}, in[:2])
+}
+
+// add supports equal shapes and broadcasting of a row, column or scalar
+func add(_ Node, in []*Tensor) (*Tensor, error) {
+	a, b := in[0], in[1]
+	if len(a.Dims) != 2 {
+		return nil, fmt.Errorf("first input must be 2-D, got %v", a.Dims)
+	}
+	if !b.broadcastable(a.Dims[0], a.Dims[1]) {
+		return nil, fmt.Errorf("shape %v does not broadcast to %v", b.Dims, a.Dims)
+	}
+	out := &Tensor{Dims: a.Dims, Data: make([]float64, len(a.Data))}
+	for i := 0; i < a.Dims[0]; i++ {
+		for j := 0; j < a.Dims[1]; j++ {
+			out.Data[i*a.Dims[1]+j] = a.Data[i*a.Dims[1]+j] + b.broadcast(i, j)
+		}
+	}
+	return out, nil
+}
+
+func relu(_ Node, in []*Tensor) (*Tensor, error) {
+	out := &Tensor{Dims: in[0].Dims, Data: make([]float64, len(in[0].Data))}
+	for i, v := range in[0].Data {
+		out.Data[i] = max(v, 0)
+	}
+	return out, nil
+}
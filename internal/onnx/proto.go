@@ -0,0 +1,133 @@
+package onnx
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Protobuf wire types used by ONNX
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// protoWriter appends protobuf fields to a buffer
+type protoWriter struct {
+	buf []byte
+}
+
+func (w *protoWriter) tag(field, wire int) {
+	w.buf = binary.AppendUvarint(w.buf, uint64(field)<<3|uint64(wire))
+}
+
+func (w *protoWriter) varint(field int, v int64) {
+	w.tag(field, wireVarint)
+	w.buf = binary.AppendUvarint(w.buf, uint64(v))
+}
+
+func (w *protoWriter) float32(field int, v float32) {
+	w.tag(field, wireFixed32)
+	w.buf = binary.LittleEndian.AppendUint32(w.buf, math.Float32bits(v))
+}
+
+func (w *protoWriter) bytes(field int, b []byte) {
+	w.tag(field, wireBytes)
+	w.buf = binary.AppendUvarint(w.buf, uint64(len(b)))
+	w.buf = append(w.buf, b...)
+}
+
+func (w *protoWriter) string(field int, s string) {
+	w.bytes(field, []byte(s))
+}
+
+// message writes an embedded message filled by fill
+func (w *protoWriter) message(field int, fill func(*protoWriter)) {
+	var m protoWriter
+	fill(&m)
+	w.bytes(field, m.buf)
+}
+
+// protoField is one decoded field: varint and fixed values in num,
+// length-delimited ones in data
+type protoField struct {
+	num  int
+	wire int
+	val  uint64
+	data []byte
+}
+
+// parseProto splits a message into fields
+func parseProto(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		key, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("bad field key")
+		}
+		b = b[n:]
+		f := protoField{num: int(key >> 3), wire: int(key & 7)}
+
+		switch f.wire {
+		case wireVarint:
+			f.val, n = binary.Uvarint(b)
+			if n <= 0 {
+				return nil, fmt.Errorf("bad varint in field %d", f.num)
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.val, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireFixed32:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.val, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		case wireBytes:
+			size, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < size {
+				return nil, fmt.Errorf("truncated field %d", f.num)
+			}
+			f.data, b = b[n:n+int(size)], b[n+int(size):]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d in field %d", f.wire, f.num)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// int64s decodes a repeated int64 field, packed or not
+func (f protoField) int64s() ([]int64, error) {
+	if f.wire == wireVarint {
+		return []int64{int64(f.val)}, nil
+	}
+	var vs []int64
+	for b := f.data; len(b) > 0; {
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("bad packed varint in field %d", f.num)
+		}
+		vs, b = append(vs, int64(v)), b[n:]
+	}
+	return vs, nil
+}
+
+// float32s decodes a repeated float field, packed or not
+func (f protoField) float32s() ([]float32, error) {
+	if f.wire == wireFixed32 {
+		return []float32{math.Float32frombits(uint32(f.val))}, nil
+	}
+	if len(f.data)%4 != 0 {
+		return nil, fmt.Errorf("packed floats in field %d have %d bytes", f.num, len(f.data))
+	}
+	vs := make([]float32, len(f.data)/4)
+	for i := range vs {
+		vs[i] = math.Float32frombits(binary.LittleEndian.Uint32(f.data[4*i:]))
+	}
+	return vs, nil
+}
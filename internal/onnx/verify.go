@@ -0,0 +1,59 @@
+package onnx
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+
+	"snakes-ml/internal/ai"
+)
+
+// VerifyResult compares an exported graph with the network it came from
+type VerifyResult struct {
+	Samples          int
+	MaxAbsError      float64 // Largest Q-value difference
+	ActionMismatches int     // States where the greedy actions differ
+}
+
+// Verify runs the exported model on samples random states as one batch
+// and compares outputs with net.Forward. Weights are exported as float32,
+// so small differences are expected.
+func Verify(net *ai.Network, data []byte, samples int, seed uint64) (VerifyResult, error) {
+	res := VerifyResult{Samples: samples}
+	m, err := Parse(data)
+	if err != nil {
+		return res, err
+	}
+	if len(m.Inputs) != 1 || len(m.Outputs) != 1 {
+		return res, fmt.Errorf("model has %d inputs and %d outputs, want 1 and 1", len(m.Inputs), len(m.Outputs))
+	}
+
+	layers := net.Layers()
+	in, out := layers[0], layers[len(layers)-1]
+	rng := rand.New(rand.NewPCG(seed, 0))
+	batch := &Tensor{Dims: []int{samples, in}, Data: make([]float64, samples*in)}
+	for i := range batch.Data {
+		batch.Data[i] = rng.Float64()*2 - 1
+	}
+
+	outputs, err := m.Run(map[string]*Tensor{m.Inputs[0]: batch})
+	if err != nil {
+		return res, err
+	}
+	q := outputs[m.Outputs[0]]
+	if len(q.Dims) != 2 || q.Dims[0] != samples || q.Dims[1] != out {
+		return res, fmt.Errorf("output shape %v, want [%d %d]", q.Dims, samples, out)
+	}
+
+	for i := 0; i < samples; i++ {
+		want := net.Forward(batch.Data[i*in : (i+1)*in])
+		got := q.Data[i*out : (i+1)*out]
+		for j := range want {
+			res.MaxAbsError = math.Max(res.MaxAbsError, math.Abs(got[j]-want[j]))
+		}
+		if ai.MaskedArgmax(got, nil) != ai.MaskedArgmax(want, nil) {
+			res.ActionMismatches++
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package policy
+
+import "snakes-ml/internal/snake"
+
+// AStar is a scripted baseline: it follows the A* shortest path to food
+// when the snake can still reach its tail after eating, otherwise it
+// chases its tail, and as a last resort takes the move with most room
+type AStar struct{}
+
+// NewAStar creates A* baseline policy
+func NewAStar() *AStar {
+	return &AStar{}
+}
+
+// SelectAction returns first step of the chosen path
+func (p *AStar) SelectAction(s *snake.Snake) int {
+	head := s.Body()[0]
+
+	if path := s.FindPath(s.Food()); len(path) > 0 && tailReachableAfter(s, path) {
+		dir, _ := s.DirectionTo(head, path[0])
+		return s.ActionFor(dir)
+	}
+
+	if s.Length() > 1 {
+		if path := s.FindPath(s.Body()[s.Length()-1]); len(path) > 0 {
+			dir, _ := s.DirectionTo(head, path[0])
+			return s.ActionFor(dir)
+		}
+	}
+
+	return roomiestAction(s)
+}
+
+// tailReachableAfter follows path on a clone and checks that the snake
+// is alive and can still reach its tail at the end of it
+func tailReachableAfter(s *snake.Snake, path []snake.Point) bool {
+	sim := s.Clone()
+	for _, next := range path {
+		dir, ok := sim.DirectionTo(sim.Body()[0], next)
+		if !ok {
+			return false
+		}
+		if _, done := sim.Step(sim.ActionFor(dir)); done {
+			return false
+		}
+	}
+
+	if sim.Length() == 1 {
+		return true
+	}
+	return len(sim.FindPath(sim.Body()[sim.Length()-1])) > 0
+}
+
+// roomiestAction returns safe action after which most cells are reachable
+func roomiestAction(s *snake.Snake) int {
+	best, bestRoom := s.ActionFor(s.CurrentDirection()), -1
+	for _, action := range s.SafeActions() {
+		sim := s.Clone()
+		if _, done := sim.Step(action); done {
+			continue
+		}
+
+		room := 0
+		for _, d := range sim.BFSDistances() {
+			if d >= 0 {
+				room++
+			}
+		}
+		if room > bestRoom {
+			best, bestRoom = action, room
+		}
+	}
+	return best
+}
@@ -0,0 +1,112 @@
+package policy
+
+import "snakes-ml/internal/snake"
+
+// Result summarizes policy performance over several episodes
+type Result struct {
+	Episodes  int
+	AvgScore  float64
+	MaxScore  int
+	AvgSteps  float64
+	AvgLength float64
+}
+
+// Evaluate plays episodes with policy on fresh snakes. Episode i uses seed
+// opts.Seed+i (1+i when unset), so different policies see the same fields.
+func Evaluate(p Policy, opts snake.Options, episodes int) Result {
+	res := Result{Episodes: episodes}
+	if episodes <= 0 {
+		return res
+	}
+
+	baseSeed := opts.Seed
+	if baseSeed == 0 {
+		baseSeed = 1
+	}
+
+	for i := 0; i < episodes; i++ {
+		opts.Seed = baseSeed + uint64(i)
+		s := snake.NewSnakeWithOptions(opts)
+
+		for {
+			if _, done := s.Step(p.SelectAction(s)); done {
+				break
+			}
+		}
+
+		res.AvgScore += float64(s.Score())
+		res.AvgSteps += float64(s.Steps())
+		res.AvgLength += float64(s.Length())
+		if s.Score() > res.MaxScore {
+			res.MaxScore = s.Score()
+		}
+	}
+
+	res.AvgScore /= float64(episodes)
+	res.AvgSteps /= float64(episodes)
+	res.AvgLength /= float64(episodes)
+	return res
+}
+
+// ArenaResult summarizes a series of multi-snake rounds
+type ArenaResult struct {
+	Episodes int
+	Wins     []int // Rounds won by each snake
+	Ties     int
+	AvgScore []float64
+}
+
+// ArenaActions asks each living snake's policy for its move
+func ArenaActions(a *snake.Arena, policies []Policy) []int {
+	actions := make([]int, a.SnakeCount())
+	for i := range actions {
+		if a.Alive(i) {
+			actions[i] = policies[i].SelectAction(a.Snake(i))
+		}
+	}
+	return actions
+}
+
+// EvaluateArena plays rounds where snake i is driven by policies[i].
+// Round i uses seed opts.Seed+i (1+i when unset).
+func EvaluateArena(policies []Policy, opts snake.ArenaOptions, episodes int) ArenaResult {
+	opts.Snakes = len(policies)
+	res := ArenaResult{
+		Episodes: episodes,
+		Wins:     make([]int, len(policies)),
+		AvgScore: make([]float64, len(policies)),
+	}
+	if episodes <= 0 {
+		return res
+	}
+
+	baseSeed := opts.Seed
+	if baseSeed == 0 {
+		baseSeed = 1
+	}
+
+	for i := 0; i < episodes; i++ {
+		opts.Seed = baseSeed + uint64(i)
+		a := snake.NewArena(opts)
+
+		for {
+			if _, done := a.Step(ArenaActions(a, policies)); done {
+				break
+			}
+		}
+
+		if w := a.Winner(); w >= 0 {
+			res.Wins[w]++
+		} else {
+			res.Ties++
+		}
+		for j := range policies {
+			res.AvgScore[j] += float64(a.Score(j))
+		}
+	}
+
+	for j := range res.AvgScore {
+		res.AvgScore[j] /= float64(episodes)
+	}
+	return res
+}
@@ -0,0 +1,306 @@
+package policy
+
+import (
+	"slices"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+)
+
+// Hamiltonian is a perfect-play baseline that follows a Hamiltonian cycle
+// over the field and takes shortcuts toward food while the snake is short.
+//
+// The cycle is built from a spanning tree over 2x2 blocks free of
+// obstacles, so it skips blocked blocks and the last row/column of odd
+// sized fields. It is rebuilt for every new field or episode and whenever
+// the field expands or the set of obstacles changes.
+// Food outside the cycle is fetched with A* when the tail stays reachable.
+type Hamiltonian struct {
+	field     *snake.Snake
+	width     int
+	height    int
+	obstacles []snake.Point // Sorted obstacles the cycle was built around
+	scratch   []snake.Point
+	next      []int // next cell along cycle, -1 if cell is not on it
+	order     []int // position of cell along cycle, -1 if cell is not on it
+	length    int
+	fallback  *AStar
+}
+
+// NewHamiltonian creates Hamiltonian cycle baseline policy
+func NewHamiltonian() *Hamiltonian {
+	return &Hamiltonian{fallback: NewAStar()}
+}
+
+// SelectAction returns next move along the cycle or a safe shortcut
+func (p *Hamiltonian) SelectAction(s *snake.Snake) int {
+	if p.field != s || s.Steps() == 0 || p.width != s.Width() || p.height != s.Height() || p.obstaclesChanged(s) {
+		p.build(s)
+	}
+
+	head := s.Body()[0]
+	headIdx := p.index(head)
+	if p.length == 0 || p.order[headIdx] < 0 {
+		return p.fallback.SelectAction(s)
+	}
+
+	food := s.Food()
+	if p.order[p.index(food)] < 0 {
+		if path := s.FindPath(food); len(path) > 0 && tailReachableAfter(s, path) {
+			dir, _ := s.DirectionTo(head, path[0])
+			return s.ActionFor(dir)
+		}
+	}
+
+	safe := s.SafeActions()
+	target := p.next[headIdx]
+	if shortcut, ok := p.shortcut(s, safe); ok {
+		target = shortcut
+	}
+
+	dir, ok := s.DirectionTo(head, p.point(target))
+	if ok && slices.Contains(safe, s.ActionFor(dir)) {
+		return s.ActionFor(dir)
+	}
+	return p.fallback.SelectAction(s)
+}
+
+// shortcut returns cycle cell adjacent to head that gets closest to food
+// without overtaking the tail. Only used when body follows cycle order.
+func (p *Hamiltonian) shortcut(s *snake.Snake, safe []int) (int, bool) {
+	body := s.Body()
+	if float64(len(body)) > float64(p.length)*config.HamiltonShortcutMaxFill || !p.aligned(body) {
+		return 0, false
+	}
+
+	head := p.index(body[0])
+	tailDist := p.length
+	if len(body) > 1 {
+		tailDist = p.distance(head, p.index(body[len(body)-1]))
+	}
+	foodDist := p.length
+	if food := p.index(s.Food()); p.order[food] >= 0 {
+		foodDist = p.distance(head, food)
+	}
+
+	best, bestDist := 0, 0
+	for _, action := range safe {
+		next, ok := neighborCell(s, body[0], s.ActionDirection(action))
+		if !ok {
+			continue
+		}
+		idx := p.index(next)
+		if p.order[idx] < 0 {
+			continue
+		}
+
+		d := p.distance(head, idx)
+		if d < tailDist-config.HamiltonShortcutMargin && d <= foodDist && d > bestDist {
+			best, bestDist = idx, d
+		}
+	}
+
+	return best, bestDist > 0
+}
+
+// aligned reports whether body cells lie on the cycle in order from tail
+// to head, so the cells in front of the head up to the tail are free
+func (p *Hamiltonian) aligned(body []snake.Point) bool {
+	span := 0
+	for i := 1; i < len(body); i++ {
+		a, b := p.index(body[i]), p.index(body[i-1])
+		if p.order[a] < 0 || p.order[b] < 0 {
+			return false
+		}
+		d := p.distance(a, b)
+		if d == 0 {
+			return false
+		}
+		span += d
+	}
+	return span < p.length
+}
+
+// distance returns number of cycle steps from cell a forward to cell b
+func (p *Hamiltonian) distance(a, b int) int {
+	return (p.order[b] - p.order[a] + p.length) % p.length
+}
+
+func (p *Hamiltonian) index(pos snake.Point) int { return pos.Y*p.width + pos.X }
+
+func (p *Hamiltonian) point(idx int) snake.Point {
+	return snake.Point{X: idx % p.width, Y: idx / p.width}
+}
+
+// neighborCell returns adjacent in-bounds cell in direction
+func neighborCell(s *snake.Snake, pos snake.Point, dir snake.Direction) (snake.Point, bool) {
+	next := pos.Add(dir.ToVector())
+	if s.WrapAround() {
+		next.X = (next.X + s.Width()) % s.Width()
+		next.Y = (next.Y + s.Height()) % s.Height()
+	}
+	return next, next.X >= 0 && next.X < s.Width() && next.Y >= 0 && next.Y < s.Height()
+}
+
+// obstaclesChanged reports whether obstacles of s differ as a set from
+// those the cycle was built around; the sorted set is kept in scratch
+func (p *Hamiltonian) obstaclesChanged(s *snake.Snake) bool {
+	p.scratch = append(p.scratch[:0], s.Obstacles()...)
+	slices.SortFunc(p.scratch, func(a, b snake.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	return !slices.Equal(p.scratch, p.obstacles)
+}
+
+// build constructs cycle for current field
+func (p *Hamiltonian) build(s *snake.Snake) {
+	p.field = s
+	p.width, p.height = s.Width(), s.Height()
+	p.obstaclesChanged(s)
+	p.obstacles, p.scratch = p.scratch, p.obstacles
+	p.length = 0
+
+	cells := p.width * p.height
+	p.next = make([]int, cells)
+	p.order = make([]int, cells)
+	for i := range p.next {
+		p.next[i], p.order[i] = -1, -1
+	}
+
+	blockedCells := make([]bool, cells)
+	for _, obs := range s.Obstacles() {
+		if obs.X >= 0 && obs.X < p.width && obs.Y >= 0 && obs.Y < p.height {
+			blockedCells[p.index(obs)] = true
+		}
+	}
+
+	bw, bh := p.width/2, p.height/2
+	usable := make([]bool, bw*bh)
+	for by := 0; by < bh; by++ {
+		for bx := 0; bx < bw; bx++ {
+			x, y := bx*2, by*2
+			usable[by*bw+bx] = !blockedCells[y*p.width+x] && !blockedCells[y*p.width+x+1] &&
+				!blockedCells[(y+1)*p.width+x] && !blockedCells[(y+1)*p.width+x+1]
+		}
+	}
+
+	root := p.rootBlock(s.Body()[0], usable, bw, bh)
+	if root < 0 {
+		return
+	}
+
+	// Обход в глубину по блокам 2x2 строит остовное дерево
+	inTree := make([]bool, bw*bh)
+	inTree[root] = true
+	p.linkBlock(root%bw, root/bw)
+	stack := []int{root}
+
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		bx, by := cur%bw, cur/bw
+
+		linked := false
+		for _, d := range [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+			nx, ny := bx+d[0], by+d[1]
+			if nx < 0 || nx >= bw || ny < 0 || ny >= bh {
+				continue
+			}
+			nb := ny*bw + nx
+			if !usable[nb] || inTree[nb] {
+				continue
+			}
+
+			inTree[nb] = true
+			p.linkBlock(nx, ny)
+			p.joinBlocks(bx, by, nx, ny)
+			stack = append(stack, nb)
+			linked = true
+			break
+		}
+
+		if !linked {
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	start := (root/bw*2)*p.width + root%bw*2
+	for idx, pos := start, 0; pos == 0 || idx != start; idx, pos = p.next[idx], pos+1 {
+		p.order[idx] = pos
+		p.length++
+	}
+}
+
+// rootBlock returns block containing head when usable, otherwise first
+// block of the largest connected group of usable blocks
+func (p *Hamiltonian) rootBlock(head snake.Point, usable []bool, bw, bh int) int {
+	if hx, hy := head.X/2, head.Y/2; hx < bw && hy < bh && usable[hy*bw+hx] {
+		return hy*bw + hx
+	}
+
+	seen := make([]bool, len(usable))
+	best, bestSize := -1, 0
+	for start := range usable {
+		if !usable[start] || seen[start] {
+			continue
+		}
+
+		size := 0
+		seen[start] = true
+		queue := []int{start}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			size++
+			bx, by := cur%bw, cur/bw
+			for _, d := range [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}} {
+				nx, ny := bx+d[0], by+d[1]
+				if nx < 0 || nx >= bw || ny < 0 || ny >= bh {
+					continue
+				}
+				if nb := ny*bw + nx; usable[nb] && !seen[nb] {
+					seen[nb] = true
+					queue = append(queue, nb)
+				}
+			}
+		}
+
+		if size > bestSize {
+			best, bestSize = start, size
+		}
+	}
+	return best
+}
+
+// blockCells returns top-left, top-right, bottom-right, bottom-left cells
+func (p *Hamiltonian) blockCells(bx, by int) (tl, tr, br, bl int) {
+	x, y := bx*2, by*2
+	return y*p.width + x, y*p.width + x + 1, (y+1)*p.width + x + 1, (y+1)*p.width + x
+}
+
+// linkBlock makes a clockwise loop inside a single block
+func (p *Hamiltonian) linkBlock(bx, by int) {
+	tl, tr, br, bl := p.blockCells(bx, by)
+	p.next[tl], p.next[tr], p.next[br], p.next[bl] = tr, br, bl, tl
+}
+
+// joinBlocks merges loops of two adjacent blocks into one
+func (p *Hamiltonian) joinBlocks(ax, ay, bx, by int) {
+	// Упорядочиваем: a слева или сверху от b
+	if bx < ax || by < ay {
+		ax, ay, bx, by = bx, by, ax, ay
+	}
+
+	_, aTR, aBR, aBL := p.blockCells(ax, ay)
+	bTL, bTR, _, bBL := p.blockCells(bx, by)
+
+	if ay == by {
+		p.next[aTR] = bTL
+		p.next[bBL] = aBR
+	} else {
+		p.next[aBR] = bTR
+		p.next[bTL] = aBL
+	}
+}
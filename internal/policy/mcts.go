@@ -0,0 +1,201 @@
+package policy
+
+import (
+	"math"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/snake"
+)
+
+// MCTSConfig holds tree search parameters
+type MCTSConfig struct {
+	Simulations int     // Simulations per move
+	Exploration float64 // PUCT exploration constant
+	Temperature float64 // Softmax temperature for Q-value priors
+	Gamma       float64 // Discount for simulated rewards
+}
+
+// DefaultMCTSConfig returns search parameters from central config
+func DefaultMCTSConfig() MCTSConfig {
+	return MCTSConfig{
+		Simulations: config.MCTSSimulations,
+		Exploration: config.MCTSExploration,
+		Temperature: config.MCTSTemperature,
+		Gamma:       config.Gamma,
+	}
+}
+
+// MCTS plans moves with Monte Carlo Tree Search on cloned snakes.
+// The Q-network provides action priors (softmax of Q-values), initial
+// edge estimates and leaf values (max Q); the most visited action wins.
+type MCTS struct {
+	net *ai.Network
+	cfg MCTSConfig
+
+	// Границы значений в дереве для нормализации Q
+	minValue float64
+	maxValue float64
+}
+
+// mctsNode stores statistics for every action taken from one state
+type mctsNode struct {
+	prior    []float64
+	estimate []float64 // Q-value from network, used until edge is visited
+	visits   []int
+	valueSum []float64
+	reward   []float64
+	done     []bool
+	legal    []bool
+	children []*mctsNode
+	total    int
+}
+
+// NewMCTS creates tree search policy over Q-network
+func NewMCTS(net *ai.Network, cfg MCTSConfig) *MCTS {
+	return &MCTS{net: net, cfg: cfg}
+}
+
+// SelectAction runs simulation budget from current state and returns
+// the action with the most visits
+func (m *MCTS) SelectAction(s *snake.Snake) int {
+	m.minValue = math.Inf(1)
+	m.maxValue = math.Inf(-1)
+
+	root, _ := m.expand(s)
+	rootSnap := s.Snapshot()
+	sim := s.Clone()
+
+	for i := 0; i < m.cfg.Simulations; i++ {
+		sim.Restore(rootSnap)
+		m.simulate(sim, root)
+	}
+
+	best := -1
+	for a := range root.visits {
+		if !root.legal[a] {
+			continue
+		}
+		if best < 0 || root.visits[a] > root.visits[best] ||
+			(root.visits[a] == root.visits[best] && root.mean(a) > root.mean(best)) {
+			best = a
+		}
+	}
+	if best < 0 {
+		return s.ActionFor(s.CurrentDirection())
+	}
+	return best
+}
+
+// expand evaluates state with network and creates node for it
+func (m *MCTS) expand(s *snake.Snake) (*mctsNode, float64) {
+	qValues := m.net.Forward(s.GetState())
+	n := len(qValues)
+
+	node := &mctsNode{
+		prior:    make([]float64, n),
+		estimate: qValues,
+		visits:   make([]int, n),
+		valueSum: make([]float64, n),
+		reward:   make([]float64, n),
+		done:     make([]bool, n),
+		legal:    make([]bool, n),
+		children: make([]*mctsNode, n),
+	}
+
+	// Разворот на 180° эквивалентен движению прямо, не тратим на него симуляции
+	mask := s.LegalActions()
+	value := math.Inf(-1)
+	for a := range node.legal {
+		node.legal[a] = a < len(mask) && mask[a]
+		if node.legal[a] && qValues[a] > value {
+			value = qValues[a]
+		}
+	}
+
+	sum := 0.0
+	for a, q := range qValues {
+		if node.legal[a] {
+			node.prior[a] = math.Exp((q - value) / m.cfg.Temperature)
+			sum += node.prior[a]
+		}
+	}
+	for a := range node.prior {
+		node.prior[a] /= sum
+	}
+
+	return node, value
+}
+
+// simulate descends tree from node, expands one leaf and backs up return
+func (m *MCTS) simulate(sim *snake.Snake, node *mctsNode) float64 {
+	a := m.selectEdge(node)
+
+	var g float64
+	if node.done[a] && node.visits[a] > 0 {
+		g = node.reward[a]
+	} else {
+		reward, done := sim.Step(a)
+		node.reward[a], node.done[a] = reward, done
+
+		switch {
+		case done:
+			g = reward
+		case node.children[a] == nil:
+			child, value := m.expand(sim)
+			node.children[a] = child
+			g = reward + m.cfg.Gamma*value
+		default:
+			g = reward + m.cfg.Gamma*m.simulate(sim, node.children[a])
+		}
+	}
+
+	node.visits[a]++
+	node.valueSum[a] += g
+	node.total++
+
+	m.minValue = math.Min(m.minValue, g)
+	m.maxValue = math.Max(m.maxValue, g)
+	return g
+}
+
+// selectEdge picks action maximizing PUCT score
+func (m *MCTS) selectEdge(node *mctsNode) int {
+	best := -1
+	bestScore := math.Inf(-1)
+	sqrtTotal := math.Sqrt(float64(node.total + 1))
+
+	for a := range node.visits {
+		if !node.legal[a] {
+			continue
+		}
+
+		q := node.estimate[a]
+		if node.visits[a] > 0 {
+			q = node.mean(a)
+		}
+
+		score := m.normalize(q) + m.cfg.Exploration*node.prior[a]*sqrtTotal/float64(1+node.visits[a])
+		if score > bestScore {
+			best, bestScore = a, score
+		}
+	}
+
+	return best
+}
+
+// normalize maps value into [0, 1] using bounds seen in the tree
+func (m *MCTS) normalize(v float64) float64 {
+	if m.maxValue <= m.minValue {
+		return 0.5
+	}
+	return math.Max(0, math.Min(1, (v-m.minValue)/(m.maxValue-m.minValue)))
+}
+
+// mean returns average backed-up value of action
+func (n *mctsNode) mean(a int) float64 {
+	if n.visits[a] == 0 {
+		return n.estimate[a]
+	}
+	return n.valueSum[a] / float64(n.visits[a])
+}
@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"snakes-ml/internal/ai"
+	"snakes-ml/internal/snake"
+)
+
+// Policy chooses next action for a snake
+type Policy interface {
+	SelectAction(s *snake.Snake) int
+}
+
+// QFunction maps a state to Q-values: *ai.Network or its int8 copy
+// *ai.QuantizedNetwork
+type QFunction interface {
+	Forward(state []float64) []float64
+}
+
+// Greedy picks action with highest Q-value, no exploration
+type Greedy struct {
+	net QFunction
+}
+
+// NewGreedy creates greedy policy over Q-network
+func NewGreedy(net QFunction) *Greedy {
+	return &Greedy{net: net}
+}
+
+// SelectAction returns argmax of Q-values over legal actions
+func (p *Greedy) SelectAction(s *snake.Snake) int {
+	return ai.MaskedArgmax(p.net.Forward(s.GetState()), s.LegalActions())
+}
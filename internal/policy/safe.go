@@ -0,0 +1,83 @@
+package policy
+
+import (
+	"math"
+
+	"snakes-ml/internal/snake"
+)
+
+// Scorer is implemented by policies that can rank every action
+type Scorer interface {
+	ActionScores(s *snake.Snake) []float64
+}
+
+// ActionScores returns Q-values for current state
+func (p *Greedy) ActionScores(s *snake.Snake) []float64 {
+	return p.net.Forward(s.GetState())
+}
+
+// SafeFilter wraps a policy and vetoes moves that lead into a region
+// smaller than the body unless the tail stays reachable. A vetoed action
+// is replaced by the best scored safe action when the base policy is a
+// Scorer, otherwise by the move with the largest reachable area.
+type SafeFilter struct {
+	base Policy
+}
+
+// NewSafeFilter wraps base policy with flood-fill safety check
+func NewSafeFilter(base Policy) *SafeFilter {
+	return &SafeFilter{base: base}
+}
+
+// SelectAction returns base action when safe, otherwise safest alternative
+func (p *SafeFilter) SelectAction(s *snake.Snake) int {
+	action := p.base.SelectAction(s)
+	dirMoves := s.MoveReachability()
+
+	// Переводим результаты по направлениям в пространство действий
+	moves := make([]snake.Reachability, s.ActionSize())
+	for a := range moves {
+		moves[a] = dirMoves[s.ActionDirection(a)]
+	}
+	if action >= 0 && action < len(moves) && isSafeMove(moves[action], s.Length()) {
+		return action
+	}
+
+	var scores []float64
+	if scorer, ok := p.base.(Scorer); ok {
+		scores = scorer.ActionScores(s)
+	}
+
+	best, bestScore := -1, math.Inf(-1)
+	for a, m := range moves {
+		if !isSafeMove(m, s.Length()) {
+			continue
+		}
+		score := float64(m.Area)
+		if a < len(scores) {
+			score = scores[a]
+		}
+		if score > bestScore {
+			best, bestScore = a, score
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+
+	// Безопасных ходов нет: выбираем ход с наибольшей областью
+	for a, m := range moves {
+		if m.Valid && (best < 0 || m.Area > moves[best].Area) {
+			best = a
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+	return action
+}
+
+// isSafeMove reports whether region after move can hold the snake
+func isSafeMove(m snake.Reachability, length int) bool {
+	return m.Valid && (m.TailReachable || m.Area >= length)
+}
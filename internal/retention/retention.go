@@ -0,0 +1,163 @@
+// Package retention decides which generation checkpoints to keep: the
+// last N, every Kth generation and the top K by evaluation score
+package retention
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/atomicfile"
+)
+
+// Policy keeps a snapshot if any of its rules does; a zero rule is off.
+// With all rules off every snapshot is kept.
+type Policy struct {
+	KeepLast  int // Newest generations
+	KeepEvery int // Generations divisible by KeepEvery
+	KeepTop   int // Best evaluated generations
+}
+
+// DefaultPolicy returns retention settings from central config
+func DefaultPolicy() Policy {
+	return Policy{
+		KeepLast:  config.RetainLastGenerations,
+		KeepEvery: config.RetainEveryGeneration,
+		KeepTop:   config.RetainTopGenerations,
+	}
+}
+
+// Snapshot is a saved generation model
+type Snapshot struct {
+	Generation int     `json:"generation"`
+	File       string  `json:"file"`
+	Score      float64 `json:"score"`
+	Evaluated  bool    `json:"evaluated"` // Files found on disk have no score
+}
+
+// Keep returns generations of snaps the policy retains
+func (p Policy) Keep(snaps []Snapshot) map[int]bool {
+	keep := make(map[int]bool, len(snaps))
+	if p.KeepLast <= 0 && p.KeepEvery <= 0 && p.KeepTop <= 0 {
+		for _, s := range snaps {
+			keep[s.Generation] = true
+		}
+		return keep
+	}
+
+	byGen := append([]Snapshot(nil), snaps...)
+	sort.Slice(byGen, func(i, j int) bool { return byGen[i].Generation > byGen[j].Generation })
+	for i, s := range byGen {
+		if i < p.KeepLast || (p.KeepEvery > 0 && s.Generation%p.KeepEvery == 0) {
+			keep[s.Generation] = true
+		}
+	}
+
+	// Stable by generation, so ties favour the newer snapshot
+	byScore := make([]Snapshot, 0, len(byGen))
+	for _, s := range byGen {
+		if s.Evaluated {
+			byScore = append(byScore, s)
+		}
+	}
+	sort.SliceStable(byScore, func(i, j int) bool { return byScore[i].Score > byScore[j].Score })
+	for i := 0; i < p.KeepTop && i < len(byScore); i++ {
+		keep[byScore[i].Generation] = true
+	}
+	return keep
+}
+
+// Index lists generation snapshots on disk with their scores
+type Index struct {
+	Snapshots []Snapshot `json:"snapshots"`
+	filename  string
+}
+
+// New creates an empty index saved to filename
+func New(filename string) *Index {
+	return &Index{filename: filename}
+}
+
+// Load reads index saved by Save
+func Load(filename string) (*Index, error) {
+	ix := New(filename)
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read retention index: %w", err)
+	}
+	if err := json.Unmarshal(data, ix); err != nil {
+		return nil, fmt.Errorf("parse retention index: %w", err)
+	}
+	return ix, nil
+}
+
+// Save writes the index
+func (ix *Index) Save() error {
+	data, err := json.MarshalIndent(ix, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal retention index: %w", err)
+	}
+	return atomicfile.WriteFile(ix.filename, data, 0644)
+}
+
+// Discover adds unindexed files named <prefix><generation><ext>, e.g.
+// generations saved before the index existed, without a score
+func (ix *Index) Discover(prefix, ext string) error {
+	files, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return err
+	}
+	known := make(map[int]bool, len(ix.Snapshots))
+	for _, s := range ix.Snapshots {
+		known[s.Generation] = true
+	}
+	for _, file := range files {
+		gen, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, prefix), ext))
+		if err != nil || known[gen] {
+			continue
+		}
+		ix.Snapshots = append(ix.Snapshots, Snapshot{Generation: gen, File: file})
+	}
+	return nil
+}
+
+// Add records a snapshot, replacing an earlier one of the same generation
+func (ix *Index) Add(s Snapshot) {
+	for i := range ix.Snapshots {
+		if ix.Snapshots[i].Generation == s.Generation {
+			ix.Snapshots[i] = s
+			return
+		}
+	}
+	ix.Snapshots = append(ix.Snapshots, s)
+}
+
+// Prune deletes files of snapshots the policy does not keep and returns
+// them. Snapshots whose file could not be deleted stay in the index.
+func (ix *Index) Prune(p Policy) ([]Snapshot, error) {
+	keep := p.Keep(ix.Snapshots)
+	var removed []Snapshot
+	var errs []error
+	kept := ix.Snapshots[:0]
+	for _, s := range ix.Snapshots {
+		if keep[s.Generation] {
+			kept = append(kept, s)
+			continue
+		}
+		if err := os.Remove(s.File); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			kept = append(kept, s)
+			continue
+		}
+		removed = append(removed, s)
+	}
+	ix.Snapshots = kept
+	return removed, errors.Join(errs...)
+}
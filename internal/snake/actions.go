@@ -0,0 +1,89 @@
+package snake
+
+import "snakes-ml/config"
+
+// ActionMode selects how Step interprets actions
+type ActionMode int
+
+const (
+	AbsoluteActions ActionMode = iota // Up, Right, Down, Left
+	RelativeActions                   // Turn left, straight, turn right
+)
+
+// Relative actions
+const (
+	TurnLeft = iota
+	Straight
+	TurnRight
+)
+
+// DefaultActionMode returns action mode selected in central config
+func DefaultActionMode() ActionMode {
+	if config.ActionMode == config.ActionModeRelative {
+		return RelativeActions
+	}
+	return AbsoluteActions
+}
+
+// ActionSize returns number of actions in mode
+func (m ActionMode) ActionSize() int {
+	if m == RelativeActions {
+		return config.RelativeActionSize
+	}
+	return config.ActionSize
+}
+
+// String returns mode name as used in config and model metadata
+func (m ActionMode) String() string {
+	if m == RelativeActions {
+		return config.ActionModeRelative
+	}
+	return config.ActionModeAbsolute
+}
+
+func (s *Snake) ActionMode() ActionMode { return s.actionMode }
+func (s *Snake) ActionSize() int        { return s.actionMode.ActionSize() }
+
+// ActionDirection returns absolute direction the action moves to
+func (s *Snake) ActionDirection(action int) Direction {
+	if s.actionMode != RelativeActions {
+		return Direction(action)
+	}
+
+	switch action {
+	case TurnLeft:
+		return (s.direction + 3) % 4
+	case TurnRight:
+		return (s.direction + 1) % 4
+	default:
+		return s.direction
+	}
+}
+
+// ActionFor returns action that moves in direction. In relative mode the
+// reverse direction maps to Straight, which is what Step would do anyway.
+func (s *Snake) ActionFor(dir Direction) int {
+	if s.actionMode != RelativeActions {
+		return int(dir)
+	}
+
+	switch dir {
+	case (s.direction + 3) % 4:
+		return TurnLeft
+	case (s.direction + 1) % 4:
+		return TurnRight
+	default:
+		return Straight
+	}
+}
+
+// LegalActions returns mask of actions that change the game: reversing
+// into the neck is ignored by Step, so the opposite direction is masked.
+// Every relative action is legal.
+func (s *Snake) LegalActions() []bool {
+	mask := make([]bool, s.ActionSize())
+	for a := range mask {
+		mask[a] = !s.direction.IsOpposite(s.ActionDirection(a))
+	}
+	return mask
+}
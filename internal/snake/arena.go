@@ -0,0 +1,457 @@
+package snake
+
+import (
+	"math/rand/v2"
+
+	"snakes-ml/config"
+)
+
+// ArenaOptions holds configuration of a multi-snake field
+type ArenaOptions struct {
+	Width      int
+	Height     int
+	WrapAround bool
+	Snakes     int    // Number of competing snakes
+	Food       int    // Food items on the field at once
+	Seed       uint64 // 0 picks a random seed
+	Features   Features
+	ActionMode ActionMode
+	Level      *Level // Fixed walls; spawn is used by the first snake
+	Layout     Layout
+	Obstacles  int // Scattered obstacles of the scatter layout
+}
+
+// DefaultArenaOptions returns arena options from central config
+func DefaultArenaOptions() ArenaOptions {
+	return ArenaOptions{
+		Width:      config.ArenaWidth,
+		Height:     config.ArenaHeight,
+		WrapAround: config.WrapAroundEnabled,
+		Snakes:     config.ArenaSnakes,
+		Food:       config.ArenaFood,
+		Features:   DefaultFeatures(),
+		ActionMode: DefaultActionMode(),
+		Layout:     DefaultLayout(),
+		Obstacles:  config.InitialObstaclesMax,
+	}
+}
+
+// Arena is a field shared by several snakes competing for food.
+//
+// Every snake is a *Snake view of the shared field: its own body, the
+// food closest to its head, and walls plus the bodies of other snakes as
+// obstacles. Views are what policies and GetState see, so single-snake
+// policies and networks work unchanged.
+//
+// All snakes move at once. A snake dies when its head hits a wall, the
+// field border or any body (tails that move away this step are free).
+// When heads meet, the longer snake survives; equal lengths both die.
+type Arena struct {
+	width, height int
+	wrapAround    bool
+	walls         []Point
+	food          []Point
+	snakes        []*Snake
+	alive         []bool
+	deathStep     []int
+	steps         int
+	maxSteps      int
+	foodCount     int
+	seed          uint64
+	rng           *rand.Rand
+	opts          ArenaOptions
+}
+
+// NewArena creates arena and places snakes
+func NewArena(opts ArenaOptions) *Arena {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	opts.Snakes = max(opts.Snakes, 1)
+	opts.Food = max(opts.Food, 1)
+
+	a := &Arena{
+		width:      opts.Width,
+		height:     opts.Height,
+		wrapAround: opts.WrapAround,
+		foodCount:  opts.Food,
+		seed:       seed,
+		rng:        rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+		opts:       opts,
+	}
+	if opts.Level != nil {
+		a.width, a.height = opts.Level.Width, opts.Level.Height
+		a.wrapAround = opts.Level.WrapAround
+	}
+
+	a.Reset()
+	return a
+}
+
+// Reset starts a new round with fresh snakes, walls and food
+func (a *Arena) Reset() {
+	a.steps = 0
+	a.maxSteps = a.width * a.height * 3
+	a.food = nil
+	a.snakes = make([]*Snake, a.opts.Snakes)
+	a.alive = make([]bool, a.opts.Snakes)
+	a.deathStep = make([]int, a.opts.Snakes)
+
+	for i := range a.snakes {
+		a.snakes[i] = &Snake{
+			width:         a.width,
+			height:        a.height,
+			wrapAround:    a.wrapAround,
+			initialSize:   a.width,
+			maxSteps:      a.maxSteps,
+			lastPositions: make([]Point, 0, 10),
+			seed:          a.seed,
+			src:           rand.NewPCG(a.seed, uint64(i)),
+			features:      a.opts.Features,
+			actionMode:    a.opts.ActionMode,
+		}
+		a.snakes[i].rng = rand.New(a.snakes[i].src)
+		a.alive[i] = true
+	}
+
+	a.placeWalls()
+	for i, s := range a.snakes {
+		head, dir := a.spawnPoint(i)
+		s.body = []Point{a.freeCellNear(head)}
+		s.direction = dir
+	}
+
+	for len(a.food) < a.foodCount {
+		if !a.spawnFood() {
+			break
+		}
+	}
+	a.syncViews()
+}
+
+// placeWalls builds walls from level or layout generator
+func (a *Arena) placeWalls() {
+	if lvl := a.opts.Level; lvl != nil {
+		a.walls = append([]Point(nil), lvl.Walls...)
+		return
+	}
+
+	g := newObstacleGrid(a.width, a.height, a.wrapAround)
+	layout := a.opts.Layout
+	if layout == LayoutMixed {
+		layout = Layout(a.rng.IntN(int(LayoutMixed)))
+	}
+	switch layout {
+	case LayoutMaze:
+		g.maze(a.rng, config.MazeOpenness)
+	case LayoutRooms:
+		g.rooms(a.rng, 0, 0, a.width, a.height)
+	case LayoutWalls:
+		g.segments(a.rng, Point{X: a.width / 2, Y: a.height / 2})
+	case LayoutScatter:
+		for i := 0; i < a.opts.Obstacles; i++ {
+			g.wall[a.rng.IntN(len(g.wall))] = true
+		}
+	}
+
+	// Места появления змеек свободны, остается одна связная область
+	for i := range a.snakes {
+		head, _ := a.spawnPoint(i)
+		for dx := -1; dx <= 1; dx++ {
+			if p, ok := g.point(head.X+dx, head.Y); ok {
+				g.wall[g.index(p)] = false
+			}
+		}
+	}
+	g.keepLargestRegion()
+	a.walls = g.walls()
+}
+
+// spawnPoint spreads snakes over rows, alternating sides and facing the
+// center. The first snake uses level spawn when there is one.
+func (a *Arena) spawnPoint(i int) (Point, Direction) {
+	if lvl := a.opts.Level; lvl != nil && i == 0 {
+		return lvl.Spawn, lvl.Direction
+	}
+
+	n := len(a.snakes)
+	y := a.height * (2*(i/2) + 1) / (2 * ((n + 1) / 2))
+	if i%2 == 0 {
+		return Point{X: a.width / 4, Y: y}, Right
+	}
+	return Point{X: a.width - 1 - a.width/4, Y: y}, Left
+}
+
+// freeCellNear returns free cell closest to p, searching in growing squares
+func (a *Arena) freeCellNear(p Point) Point {
+	for r := 0; r < max(a.width, a.height); r++ {
+		for dy := -r; dy <= r; dy++ {
+			for dx := -r; dx <= r; dx++ {
+				if max(abs(dx), abs(dy)) != r {
+					continue
+				}
+				q := Point{X: p.X + dx, Y: p.Y + dy}
+				if q.X >= 0 && q.X < a.width && q.Y >= 0 && q.Y < a.height && a.isCellFree(q) {
+					return q
+				}
+			}
+		}
+	}
+	return p
+}
+
+// spawnFood adds one food item on a random free cell
+func (a *Arena) spawnFood() bool {
+	for attempt := 0; attempt < 1000; attempt++ {
+		p := Point{X: a.rng.IntN(a.width), Y: a.rng.IntN(a.height)}
+		if a.isCellFree(p) {
+			a.food = append(a.food, p)
+			return true
+		}
+	}
+	return false
+}
+
+// isCellFree checks walls, food and bodies of living snakes
+func (a *Arena) isCellFree(p Point) bool {
+	for _, w := range a.walls {
+		if w == p {
+			return false
+		}
+	}
+	for _, f := range a.food {
+		if f == p {
+			return false
+		}
+	}
+	for i, s := range a.snakes {
+		if !a.alive[i] {
+			continue
+		}
+		for _, seg := range s.body {
+			if seg == p {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// syncViews updates every snake view with the shared field
+func (a *Arena) syncViews() {
+	for i, s := range a.snakes {
+		s.steps = a.steps
+		s.obstacles = append(s.obstacles[:0], a.walls...)
+		for j, other := range a.snakes {
+			if j != i && a.alive[j] {
+				s.obstacles = append(s.obstacles, other.body...)
+			}
+		}
+		s.food = a.nearestFood(s.body[0])
+	}
+}
+
+// nearestFood returns food closest to pos
+func (a *Arena) nearestFood(pos Point) Point {
+	if len(a.food) == 0 {
+		return pos
+	}
+	best, bestDist := a.food[0], a.distance(pos, a.food[0])
+	for _, f := range a.food[1:] {
+		if d := a.distance(pos, f); d < bestDist {
+			best, bestDist = f, d
+		}
+	}
+	return best
+}
+
+// distance is Manhattan distance, shortest way around on wrapping fields
+func (a *Arena) distance(p, q Point) int {
+	dx, dy := abs(p.X-q.X), abs(p.Y-q.Y)
+	if a.wrapAround {
+		dx = min(dx, a.width-dx)
+		dy = min(dy, a.height-dy)
+	}
+	return dx + dy
+}
+
+// Step moves all living snakes at once; actions of dead snakes are
+// ignored. Returns reward of every snake and whether the round is over.
+func (a *Arena) Step(actions []int) ([]float64, bool) {
+	a.steps++
+	n := len(a.snakes)
+	rewards := make([]float64, n)
+	heads := make([]Point, n)
+	eats := make([]bool, n)
+	dead := make([]bool, n)
+
+	for i, s := range a.snakes {
+		if !a.alive[i] {
+			continue
+		}
+		newDir := s.ActionDirection(actions[i])
+		if !s.direction.IsOpposite(newDir) {
+			s.direction = newDir
+		}
+
+		delta := s.direction.ToVector()
+		head := s.body[0].Add(delta)
+		if a.wrapAround {
+			head = s.normalizePos(head)
+		} else if !s.inBounds(head) {
+			dead[i] = true
+		}
+		heads[i] = head
+		for _, f := range a.food {
+			if f == head {
+				eats[i] = true
+			}
+		}
+	}
+
+	// Столкновения считаются по телам после хода: хвост уходит, если
+	// змейка не ест
+	for i := range a.snakes {
+		if !a.alive[i] || dead[i] {
+			continue
+		}
+		for _, w := range a.walls {
+			if heads[i] == w {
+				dead[i] = true
+			}
+		}
+		for j, other := range a.snakes {
+			if !a.alive[j] {
+				continue
+			}
+			body := other.body
+			if !eats[j] {
+				body = body[:len(body)-1]
+			}
+			for _, seg := range body {
+				if heads[i] == seg {
+					dead[i] = true
+				}
+			}
+			// Встречное движение через одну клетку и обмен клетками (у змеек
+			// длины 1 хвост уже ушёл) решаются одинаково: короткая погибает
+			headOn := heads[i] == heads[j]
+			swap := heads[i] == other.body[0] && heads[j] == a.snakes[i].body[0]
+			if j != i && (headOn || swap) && len(a.snakes[i].body) <= len(other.body) {
+				dead[i] = true
+			}
+		}
+	}
+
+	for i, s := range a.snakes {
+		if !a.alive[i] {
+			continue
+		}
+		if dead[i] {
+			a.alive[i] = false
+			a.deathStep[i] = a.steps
+			rewards[i] = config.RewardDeath
+			continue
+		}
+
+		oldDist := a.distance(s.body[0], s.food)
+		s.body = append([]Point{heads[i]}, s.body...)
+		if eats[i] {
+			s.score++
+			rewards[i] = config.RewardFood
+			a.removeFood(heads[i])
+			continue
+		}
+		s.body = s.body[:len(s.body)-1]
+
+		rewards[i] = config.RewardStep
+		if a.distance(heads[i], s.food) < oldDist {
+			rewards[i] += config.RewardMoveToFood
+		} else {
+			rewards[i] += config.RewardMoveFromFood
+		}
+	}
+
+	for len(a.food) < a.foodCount {
+		if !a.spawnFood() {
+			break
+		}
+	}
+	a.syncViews()
+
+	done := a.AliveCount() == 0 || (n > 1 && a.AliveCount() == 1) || a.steps >= a.maxSteps
+	if done {
+		for i := range a.snakes {
+			if a.alive[i] {
+				a.deathStep[i] = a.steps
+			}
+		}
+	}
+	return rewards, done
+}
+
+func (a *Arena) removeFood(p Point) {
+	for i, f := range a.food {
+		if f == p {
+			a.food = append(a.food[:i], a.food[i+1:]...)
+			return
+		}
+	}
+}
+
+// Compare ranks snake i against snake j: higher score wins, equal scores
+// go to the snake that lived longer. Returns 1, -1 or 0 for a tie.
+func (a *Arena) Compare(i, j int) int {
+	si, sj := a.snakes[i].score, a.snakes[j].score
+	switch {
+	case si > sj || si == sj && a.deathStep[i] > a.deathStep[j]:
+		return 1
+	case si < sj || a.deathStep[i] < a.deathStep[j]:
+		return -1
+	}
+	return 0
+}
+
+// Winner returns index of the snake ranked first by Compare. Returns -1
+// on a full tie.
+func (a *Arena) Winner() int {
+	winner, tie := 0, false
+	for i := 1; i < len(a.snakes); i++ {
+		switch a.Compare(i, winner) {
+		case 1:
+			winner, tie = i, false
+		case 0:
+			tie = true
+		}
+	}
+	if tie {
+		return -1
+	}
+	return winner
+}
+
+// AliveCount returns number of living snakes
+func (a *Arena) AliveCount() int {
+	count := 0
+	for _, alive := range a.alive {
+		if alive {
+			count++
+		}
+	}
+	return count
+}
+
+// Getters
+func (a *Arena) Width() int                { return a.width }
+func (a *Arena) Height() int               { return a.height }
+func (a *Arena) WrapAround() bool          { return a.wrapAround }
+func (a *Arena) Walls() []Point            { return a.walls }
+func (a *Arena) Food() []Point             { return a.food }
+func (a *Arena) Steps() int                { return a.steps }
+func (a *Arena) SnakeCount() int           { return len(a.snakes) }
+func (a *Arena) Snake(i int) *Snake        { return a.snakes[i] }
+func (a *Arena) Alive(i int) bool          { return a.alive[i] }
+func (a *Arena) Score(i int) int           { return a.snakes[i].score }
+func (a *Arena) State(i int) []float64     { return a.snakes[i].GetState() }
+func (a *Arena) LegalActions(i int) []bool { return a.snakes[i].LegalActions() }
@@ -0,0 +1,387 @@
+package snake
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+
+	"snakes-ml/config"
+)
+
+// EpisodeVersion is the current episode file format version.
+// Version 2 added fixed food sequence and obstacle interval.
+const EpisodeVersion = 2
+
+// EventType identifies a recorded episode event
+type EventType string
+
+const (
+	EventAction   EventType = "action"
+	EventFood     EventType = "food"
+	EventObstacle EventType = "obstacle"
+	EventExpand   EventType = "expand"
+	EventEnd      EventType = "end"
+)
+
+// EpisodeHeader holds the full field state at the moment recording started
+type EpisodeHeader struct {
+	Version       int        `json:"version"`
+	Seed          uint64     `json:"seed"`
+	RNG           []byte     `json:"rng"`
+	Width         int        `json:"width"`
+	Height        int        `json:"height"`
+	InitialSize   int        `json:"initial_size"`
+	MaxSteps      int        `json:"max_steps"`
+	WrapAround    bool       `json:"wrap_around"`
+	DynamicSize   bool       `json:"dynamic_size"`
+	Body          []Point    `json:"body"`
+	Food          Point      `json:"food"`
+	Obstacles     []Point    `json:"obstacles"`
+	Direction     Direction  `json:"direction"`
+	Score         int        `json:"score"`
+	Steps         int        `json:"steps"`
+	LastPositions []Point    `json:"last_positions,omitempty"`
+	Features      Features   `json:"features,omitempty"`
+	ActionMode    ActionMode `json:"action_mode,omitempty"`
+
+	FoodSequence     []Point `json:"food_sequence,omitempty"`
+	FoodIndex        int     `json:"food_index,omitempty"`
+	ObstacleInterval int     `json:"obstacle_interval"`
+	ObstaclesMin     int     `json:"obstacles_min,omitempty"`
+	ObstaclesMax     int     `json:"obstacles_max,omitempty"`
+	Layout           Layout  `json:"layout,omitempty"`
+}
+
+// Event is a single line of an episode file. Only the fields relevant
+// to the event type are set; short keys keep the file compact.
+type Event struct {
+	Type   EventType `json:"t"`
+	Step   int       `json:"s"`
+	Action int       `json:"a,omitempty"`
+	X      int       `json:"x,omitempty"`
+	Y      int       `json:"y,omitempty"`
+	Width  int       `json:"w,omitempty"`
+	Height int       `json:"h,omitempty"`
+	Score  int       `json:"score,omitempty"`
+	Length int       `json:"len,omitempty"`
+}
+
+// Episode is a recorded game: initial field plus every event that followed
+type Episode struct {
+	Header EpisodeHeader
+	Events []Event
+}
+
+// Recorder collects events of a snake while it is being played
+type Recorder struct {
+	header EpisodeHeader
+	events []Event
+}
+
+// StartRecording captures the current field and logs all following steps.
+// Any previous recorder attached to the snake is replaced.
+func (s *Snake) StartRecording() *Recorder {
+	rngState, _ := s.src.MarshalBinary()
+
+	r := &Recorder{
+		header: EpisodeHeader{
+			Version:       EpisodeVersion,
+			Seed:          s.seed,
+			RNG:           rngState,
+			Width:         s.width,
+			Height:        s.height,
+			InitialSize:   s.initialSize,
+			MaxSteps:      s.maxSteps,
+			WrapAround:    s.wrapAround,
+			DynamicSize:   s.dynamicSize,
+			Body:          append([]Point(nil), s.body...),
+			Food:          s.food,
+			Obstacles:     append([]Point(nil), s.obstacles...),
+			Direction:     s.direction,
+			Score:         s.score,
+			Steps:         s.steps,
+			LastPositions: append([]Point(nil), s.lastPositions...),
+			Features:      s.features,
+			ActionMode:    s.actionMode,
+
+			FoodSequence:     s.foodSequence,
+			FoodIndex:        s.foodIndex,
+			ObstacleInterval: s.obstacleInterval,
+			ObstaclesMin:     s.obstaclesMin,
+			ObstaclesMax:     s.obstaclesMax,
+			Layout:           s.layout,
+		},
+	}
+
+	s.recorder = r
+	return r
+}
+
+// StopRecording detaches the recorder from the snake
+func (s *Snake) StopRecording() {
+	s.recorder = nil
+}
+
+func (r *Recorder) recordAction(step, action int) {
+	r.events = append(r.events, Event{Type: EventAction, Step: step, Action: action})
+}
+
+func (r *Recorder) recordFood(step int, pos Point) {
+	r.events = append(r.events, Event{Type: EventFood, Step: step, X: pos.X, Y: pos.Y})
+}
+
+func (r *Recorder) recordObstacle(step int, pos Point) {
+	r.events = append(r.events, Event{Type: EventObstacle, Step: step, X: pos.X, Y: pos.Y})
+}
+
+func (r *Recorder) recordExpand(step, width, height int) {
+	r.events = append(r.events, Event{Type: EventExpand, Step: step, Width: width, Height: height})
+}
+
+func (r *Recorder) recordEnd(s *Snake) {
+	r.events = append(r.events, Event{Type: EventEnd, Step: s.steps, Score: s.score, Length: len(s.body)})
+}
+
+// Episode returns a copy of everything recorded so far
+func (r *Recorder) Episode() *Episode {
+	return &Episode{
+		Header: r.header,
+		Events: append([]Event(nil), r.events...),
+	}
+}
+
+// Save writes recorded episode to file
+func (r *Recorder) Save(filename string) error {
+	return r.Episode().Save(filename)
+}
+
+// Save writes episode as JSON Lines: header first, then one event per line
+func (e *Episode) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("create episode file: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+
+	if err := enc.Encode(e.Header); err != nil {
+		return fmt.Errorf("encode header: %w", err)
+	}
+	for _, ev := range e.Events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("encode event: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("write episode file: %w", err)
+	}
+	return f.Close()
+}
+
+// LoadEpisode reads episode written by Episode.Save
+func LoadEpisode(filename string) (*Episode, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open episode file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read header: %w", err)
+		}
+		return nil, fmt.Errorf("empty episode file")
+	}
+
+	e := &Episode{}
+	if err := json.Unmarshal(scanner.Bytes(), &e.Header); err != nil {
+		return nil, fmt.Errorf("unmarshal header: %w", err)
+	}
+	switch e.Header.Version {
+	case 1:
+		// До версии 2 препятствия добавлялись всегда с интервалом из конфига
+		e.Header.ObstacleInterval = config.ObstacleAddInterval
+		e.Header.Version = EpisodeVersion
+	case EpisodeVersion:
+	default:
+		return nil, fmt.Errorf("unsupported episode version %d", e.Header.Version)
+	}
+
+	line := 1
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var ev Event
+		if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+			return nil, fmt.Errorf("unmarshal event at line %d: %w", line, err)
+		}
+		e.Events = append(e.Events, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read events: %w", err)
+	}
+
+	return e, nil
+}
+
+// Actions returns recorded actions in order
+func (e *Episode) Actions() []int {
+	actions := make([]int, 0, len(e.Events))
+	for _, ev := range e.Events {
+		if ev.Type == EventAction {
+			actions = append(actions, ev.Action)
+		}
+	}
+	return actions
+}
+
+// Len returns number of recorded steps
+func (e *Episode) Len() int {
+	return len(e.Actions())
+}
+
+// Final returns end event, ok is false if episode was cut before game over
+func (e *Episode) Final() (Event, bool) {
+	if len(e.Events) > 0 && e.Events[len(e.Events)-1].Type == EventEnd {
+		return e.Events[len(e.Events)-1], true
+	}
+	return Event{}, false
+}
+
+// NewSnake rebuilds snake in the state it had when recording started
+func (e *Episode) NewSnake() (*Snake, error) {
+	h := e.Header
+
+	src := &rand.PCG{}
+	if err := src.UnmarshalBinary(h.RNG); err != nil {
+		return nil, fmt.Errorf("restore rng state: %w", err)
+	}
+	if len(h.Body) == 0 {
+		return nil, fmt.Errorf("episode header has empty body")
+	}
+
+	s := &Snake{
+		width:            h.Width,
+		height:           h.Height,
+		body:             append([]Point(nil), h.Body...),
+		food:             h.Food,
+		obstacles:        append([]Point(nil), h.Obstacles...),
+		direction:        h.Direction,
+		score:            h.Score,
+		steps:            h.Steps,
+		maxSteps:         h.MaxSteps,
+		wrapAround:       h.WrapAround,
+		dynamicSize:      h.DynamicSize,
+		initialSize:      h.InitialSize,
+		lastPositions:    append(make([]Point, 0, 10), h.LastPositions...),
+		seed:             h.Seed,
+		src:              src,
+		features:         h.Features,
+		actionMode:       h.ActionMode,
+		foodSequence:     h.FoodSequence,
+		foodIndex:        h.FoodIndex,
+		obstacleInterval: h.ObstacleInterval,
+		obstaclesMin:     h.ObstaclesMin,
+		obstaclesMax:     h.ObstaclesMax,
+		layout:           h.Layout,
+	}
+	s.rng = rand.New(s.src)
+	return s, nil
+}
+
+// Replay rebuilds snake and re-steps first n recorded actions
+func (e *Episode) Replay(n int) (*Snake, error) {
+	r, err := NewReplayer(e)
+	if err != nil {
+		return nil, err
+	}
+	for r.Position() < n && !r.Done() {
+		if err := r.Step(); err != nil {
+			return nil, err
+		}
+	}
+	return r.Snake(), nil
+}
+
+// Verify replays the whole episode and checks it matches the recording
+func (e *Episode) Verify() error {
+	_, err := e.Replay(e.Len())
+	return err
+}
+
+// Replayer re-steps a recorded episode one action at a time and checks
+// that every food spawn, obstacle and expansion matches the recording
+type Replayer struct {
+	episode *Episode
+	actions []int
+	snake   *Snake
+	check   *Recorder
+	pos     int
+	reward  float64
+	done    bool
+}
+
+// NewReplayer creates replayer positioned at the start of the episode
+func NewReplayer(e *Episode) (*Replayer, error) {
+	s, err := e.NewSnake()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Replayer{
+		episode: e,
+		actions: e.Actions(),
+		snake:   s,
+	}
+	r.check = s.StartRecording()
+	return r, nil
+}
+
+// Step applies next recorded action
+func (r *Replayer) Step() error {
+	if r.Done() {
+		return fmt.Errorf("episode already finished at step %d", r.pos)
+	}
+
+	from := len(r.check.events)
+	r.reward, r.done = r.snake.Step(r.actions[r.pos])
+	r.pos++
+
+	for i := from; i < len(r.check.events); i++ {
+		got := r.check.events[i]
+		if i >= len(r.episode.Events) {
+			return fmt.Errorf("episode diverged at step %d: unexpected %s event", got.Step, got.Type)
+		}
+		if want := r.episode.Events[i]; got != want {
+			return fmt.Errorf("episode diverged at step %d: recorded %+v, replayed %+v", got.Step, want, got)
+		}
+	}
+
+	return nil
+}
+
+// Snake returns replayed snake at current position
+func (r *Replayer) Snake() *Snake { return r.snake }
+
+// Reward returns reward of the last replayed step
+func (r *Replayer) Reward() float64 { return r.reward }
+
+// Position returns number of actions applied so far
+func (r *Replayer) Position() int { return r.pos }
+
+// Len returns total number of recorded actions
+func (r *Replayer) Len() int { return len(r.actions) }
+
+// Done reports whether replay reached game over or end of recording
+func (r *Replayer) Done() bool { return r.done || r.pos >= len(r.actions) }
@@ -0,0 +1,109 @@
+package snake
+
+// Reachability describes the region the head can reach after a move
+type Reachability struct {
+	Valid         bool // Move does not end the game immediately
+	Area          int  // Cells reachable from the new head position
+	TailReachable bool // Tail end of the body can be reached
+}
+
+// MoveReachability flood fills the field after each candidate move,
+// indexed by Direction. Reversing is a no-op, so it is reported invalid.
+func (s *Snake) MoveReachability() [4]Reachability {
+	var result [4]Reachability
+
+	head := s.body[0]
+	times := s.vacateTimes()
+	for _, dir := range s.firstMoves() {
+		next, ok := s.neighbor(head, dir)
+		if !ok || times[s.cellIndex(next)] > 1 {
+			continue
+		}
+
+		// Если съедаем еду, хвост на этом ходу не двигается
+		shift := 0
+		if next.Equal(s.food) {
+			shift = 1
+		}
+
+		area, tail := s.floodFill(times, next, shift)
+		result[dir] = Reachability{Valid: true, Area: area, TailReachable: tail}
+	}
+
+	return result
+}
+
+// ReachableArea flood fills from position as if the head had just moved
+// there and reports reachable cell count and whether the tail is reachable
+func (s *Snake) ReachableArea(from Point) (int, bool) {
+	from = s.normalizePos(from)
+	if !s.inBounds(from) {
+		return 0, false
+	}
+	return s.floodFill(s.vacateTimes(), from, 0)
+}
+
+// floodFill counts cells reachable from start (entered at move 1). A body
+// cell can be entered once its segment has left, shift delays that when
+// the snake grows. The tail counts as reachable when the region touches it.
+func (s *Snake) floodFill(times []int, start Point, shift int) (int, bool) {
+	// Хвост после хода: предпоследний сегмент, если змейка не растет
+	tail := s.body[len(s.body)-1]
+	if shift == 0 && len(s.body) > 1 {
+		tail = s.body[len(s.body)-2]
+	}
+	tailReached := shift == 0 && len(s.body) == 1
+
+	arrival := make([]int, len(times))
+	startIdx := s.cellIndex(start)
+	arrival[startIdx] = 1
+	queue := []Point{start}
+	area := 0
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		area++
+		t := arrival[s.cellIndex(pos)]
+
+		for _, dir := range []Direction{Up, Right, Down, Left} {
+			next, ok := s.neighbor(pos, dir)
+			if !ok {
+				continue
+			}
+			if next.Equal(tail) {
+				tailReached = true
+			}
+
+			idx := s.cellIndex(next)
+			if arrival[idx] > 0 || times[idx] == blocked || times[idx]+shift > t+1 {
+				continue
+			}
+
+			arrival[idx] = t + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return area, tailReached
+}
+
+// floodFeatureValues returns reachable area share and tail reachability
+// for moves Up, Right, Down, Left
+func (s *Snake) floodFeatureValues() []float64 {
+	moves := s.MoveReachability()
+	cells := float64(s.width * s.height)
+
+	values := make([]float64, 0, 8)
+	for _, m := range moves {
+		values = append(values, float64(m.Area)/cells)
+	}
+	for _, m := range moves {
+		if m.TailReachable {
+			values = append(values, 1)
+		} else {
+			values = append(values, 0)
+		}
+	}
+	return values
+}
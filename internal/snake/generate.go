@@ -0,0 +1,346 @@
+package snake
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"snakes-ml/config"
+)
+
+// Layout selects how obstacles of a random field are generated
+type Layout int
+
+const (
+	LayoutScatter Layout = iota // Single cells, InitialObstaclesMin..Max of them
+	LayoutMaze                  // Recursive-backtracker maze with extra openings
+	LayoutRooms                 // Rooms split by walls with doors
+	LayoutWalls                 // Random straight wall segments
+	LayoutMixed                 // One of the above, chosen per episode
+)
+
+var layoutNames = [...]string{
+	LayoutScatter: "scatter",
+	LayoutMaze:    "maze",
+	LayoutRooms:   "rooms",
+	LayoutWalls:   "walls",
+	LayoutMixed:   "mixed",
+}
+
+func (l Layout) String() string {
+	if l >= 0 && int(l) < len(layoutNames) {
+		return layoutNames[l]
+	}
+	return fmt.Sprintf("Layout(%d)", int(l))
+}
+
+// ParseLayout converts layout name to Layout
+func ParseLayout(name string) (Layout, error) {
+	for l, n := range layoutNames {
+		if strings.EqualFold(name, n) {
+			return Layout(l), nil
+		}
+	}
+	return LayoutScatter, fmt.Errorf("unknown obstacle layout %q", name)
+}
+
+// DefaultLayout returns obstacle layout from central config
+func DefaultLayout() Layout {
+	l, err := ParseLayout(config.ObstacleLayout)
+	if err != nil {
+		return LayoutScatter
+	}
+	return l
+}
+
+// generateObstacles builds obstacles of a generated layout around the
+// snake. Cells cut off from the head are filled in, so every free cell
+// stays reachable.
+func (s *Snake) generateObstacles(layout Layout) {
+	g := newObstacleGrid(s.width, s.height, s.wrapAround)
+	switch layout {
+	case LayoutMaze:
+		g.maze(s.rng, config.MazeOpenness)
+	case LayoutRooms:
+		g.rooms(s.rng, 0, 0, s.width, s.height)
+	case LayoutWalls:
+		g.segments(s.rng, s.body[0])
+	}
+
+	// Вокруг головы всегда остается свободное место для первых ходов
+	head := s.body[0]
+	r := config.LayoutSpawnClearance
+	for dy := -r; dy <= r; dy++ {
+		for dx := -r; dx <= r; dx++ {
+			if p, ok := g.point(head.X+dx, head.Y+dy); ok {
+				g.wall[g.index(p)] = false
+			}
+		}
+	}
+
+	g.fillUnreachable(head)
+	s.obstacles = g.walls()
+}
+
+// obstacleGrid is a wall map used while generating a layout
+type obstacleGrid struct {
+	width, height int
+	wrap          bool
+	wall          []bool
+}
+
+func newObstacleGrid(width, height int, wrap bool) *obstacleGrid {
+	return &obstacleGrid{width: width, height: height, wrap: wrap, wall: make([]bool, width*height)}
+}
+
+func (g *obstacleGrid) index(p Point) int { return p.Y*g.width + p.X }
+
+// point returns cell at x, y, wrapping coordinates when the field wraps
+func (g *obstacleGrid) point(x, y int) (Point, bool) {
+	if g.wrap {
+		return Point{X: (x%g.width + g.width) % g.width, Y: (y%g.height + g.height) % g.height}, true
+	}
+	return Point{X: x, Y: y}, x >= 0 && x < g.width && y >= 0 && y < g.height
+}
+
+// reachable marks free cells reachable from start
+func (g *obstacleGrid) reachable(start Point) []bool {
+	seen := make([]bool, len(g.wall))
+	if g.wall[g.index(start)] {
+		return seen
+	}
+
+	seen[g.index(start)] = true
+	queue := []Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, d := range [...]Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			n, ok := g.point(p.X+d.X, p.Y+d.Y)
+			if !ok || g.wall[g.index(n)] || seen[g.index(n)] {
+				continue
+			}
+			seen[g.index(n)] = true
+			queue = append(queue, n)
+		}
+	}
+	return seen
+}
+
+// fillUnreachable turns free cells cut off from start into walls
+func (g *obstacleGrid) fillUnreachable(start Point) {
+	seen := g.reachable(start)
+	for i := range g.wall {
+		if !seen[i] {
+			g.wall[i] = true
+		}
+	}
+}
+
+// keepLargestRegion fills every free region except the largest one and
+// returns a cell of the kept region
+func (g *obstacleGrid) keepLargestRegion() (Point, bool) {
+	seen := make([]bool, len(g.wall))
+	var best []bool
+	bestArea, bestStart := 0, -1
+	for i := range g.wall {
+		if g.wall[i] || seen[i] {
+			continue
+		}
+		region := g.reachable(Point{X: i % g.width, Y: i / g.width})
+		area := 0
+		for j, r := range region {
+			if r {
+				seen[j] = true
+				area++
+			}
+		}
+		if area > bestArea {
+			best, bestArea, bestStart = region, area, i
+		}
+	}
+	if bestStart < 0 {
+		return Point{}, false
+	}
+
+	for i := range g.wall {
+		if !best[i] {
+			g.wall[i] = true
+		}
+	}
+	return Point{X: bestStart % g.width, Y: bestStart / g.width}, true
+}
+
+// connected reports whether all free cells are reachable from start
+func (g *obstacleGrid) connected(start Point) bool {
+	seen := g.reachable(start)
+	for i := range g.wall {
+		if !g.wall[i] && !seen[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *obstacleGrid) walls() []Point {
+	var walls []Point
+	for i, w := range g.wall {
+		if w {
+			walls = append(walls, Point{X: i % g.width, Y: i / g.width})
+		}
+	}
+	return walls
+}
+
+// maze carves a recursive-backtracker maze: passages run through even
+// cells, walls sit on odd rows and columns. Openness is the share of
+// remaining inner walls knocked out to create loops.
+func (g *obstacleGrid) maze(rng *rand.Rand, openness float64) {
+	cols, rows := (g.width+1)/2, (g.height+1)/2
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			g.wall[y*g.width+x] = x%2 == 1 || y%2 == 1
+		}
+	}
+
+	visited := make([]bool, cols*rows)
+	stack := []Point{{X: rng.IntN(cols), Y: rng.IntN(rows)}}
+	visited[stack[0].Y*cols+stack[0].X] = true
+
+	for len(stack) > 0 {
+		c := stack[len(stack)-1]
+
+		var next []Point
+		for _, d := range [...]Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			n := Point{X: c.X + d.X, Y: c.Y + d.Y}
+			if g.wrap {
+				n = Point{X: (n.X + cols) % cols, Y: (n.Y + rows) % rows}
+			}
+			if n.X < 0 || n.X >= cols || n.Y < 0 || n.Y >= rows || visited[n.Y*cols+n.X] {
+				continue
+			}
+			next = append(next, n)
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		n := next[rng.IntN(len(next))]
+		visited[n.Y*cols+n.X] = true
+		stack = append(stack, n)
+
+		// Стена между клетками лабиринта (с учетом перехода через край)
+		dx, dy := n.X-c.X, n.Y-c.Y
+		if dx > 1 || dx < -1 {
+			dx = -dx / (cols - 1)
+		}
+		if dy > 1 || dy < -1 {
+			dy = -dy / (rows - 1)
+		}
+		if p, ok := g.point(2*c.X+dx, 2*c.Y+dy); ok {
+			g.wall[g.index(p)] = false
+		}
+	}
+
+	// Нечетный край без соседних клеток остается открытым
+	if g.width%2 == 0 {
+		for y := 0; y < g.height; y++ {
+			g.wall[y*g.width+g.width-1] = false
+		}
+	}
+	if g.height%2 == 0 {
+		for x := 0; x < g.width; x++ {
+			g.wall[(g.height-1)*g.width+x] = false
+		}
+	}
+
+	// Стены между двумя проходами убираются ради петель; столбы на
+	// пересечении нечетных линий остаются
+	for y := 0; y < g.height; y++ {
+		for x := 0; x < g.width; x++ {
+			if x%2 == 1 && y%2 == 1 {
+				continue
+			}
+			if g.wall[y*g.width+x] && rng.Float64() < openness {
+				g.wall[y*g.width+x] = false
+			}
+		}
+	}
+}
+
+// rooms splits the rectangle with a wall that has a door, then splits
+// both halves again while they are larger than two rooms
+func (g *obstacleGrid) rooms(rng *rand.Rand, x, y, w, h int) {
+	minSize := config.RoomMinSize
+	canSplitX := w >= 2*minSize+1
+	canSplitY := h >= 2*minSize+1
+	if !canSplitX && !canSplitY {
+		return
+	}
+
+	vertical := canSplitX && (!canSplitY || w > h || w == h && rng.IntN(2) == 0)
+	if vertical {
+		wx := x + minSize + rng.IntN(w-2*minSize)
+		g.wallLine(rng, wx, y, 0, 1, h)
+		g.rooms(rng, x, y, wx-x, h)
+		g.rooms(rng, wx+1, y, x+w-wx-1, h)
+		return
+	}
+
+	wy := y + minSize + rng.IntN(h-2*minSize)
+	g.wallLine(rng, x, wy, 1, 0, w)
+	g.rooms(rng, x, y, w, wy-y)
+	g.rooms(rng, x, wy+1, w, y+h-wy-1)
+}
+
+// wallLine draws a wall of given length with a door of RoomDoorWidth
+func (g *obstacleGrid) wallLine(rng *rand.Rand, x, y, dx, dy, length int) {
+	door := min(config.RoomDoorWidth, length)
+	doorAt := rng.IntN(length - door + 1)
+	for i := 0; i < length; i++ {
+		if i >= doorAt && i < doorAt+door {
+			continue
+		}
+		g.wall[(y+i*dy)*g.width+x+i*dx] = true
+	}
+}
+
+// segments places straight walls one by one, dropping any segment
+// that would split the free area
+func (g *obstacleGrid) segments(rng *rand.Rand, head Point) {
+	count := config.WallSegmentsMin + rng.IntN(config.WallSegmentsMax-config.WallSegmentsMin+1)
+	for placed, attempt := 0, 0; placed < count && attempt < count*20; attempt++ {
+		length := config.WallSegmentLenMin + rng.IntN(config.WallSegmentLenMax-config.WallSegmentLenMin+1)
+		dx, dy := 1, 0
+		if rng.IntN(2) == 0 {
+			dx, dy = 0, 1
+		}
+		x, y := rng.IntN(g.width), rng.IntN(g.height)
+
+		var cells []int
+		for i := 0; i < length; i++ {
+			p, ok := g.point(x+i*dx, y+i*dy)
+			if !ok {
+				break
+			}
+			if idx := g.index(p); !g.wall[idx] {
+				cells = append(cells, idx)
+			}
+		}
+		if len(cells) == 0 {
+			continue
+		}
+
+		for _, i := range cells {
+			g.wall[i] = true
+		}
+		if g.wall[g.index(head)] || !g.connected(head) {
+			for _, i := range cells {
+				g.wall[i] = false
+			}
+			continue
+		}
+		placed++
+	}
+}
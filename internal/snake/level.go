@@ -0,0 +1,289 @@
+package snake
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Level is a hand-designed field: fixed walls, spawn point and
+// optionally a fixed sequence of food positions
+type Level struct {
+	Name       string
+	Width      int
+	Height     int
+	WrapAround bool
+	Walls      []Point
+	Spawn      Point
+	Direction  Direction
+	Food       []Point // Spawned in order and repeated; empty means random food
+}
+
+// Level file format (plain text):
+//
+//	; comment
+//	name: Corridors
+//	wrap: false
+//	dir: right
+//	size: 20x15
+//	food: 3,4
+//	map:
+//	####################
+//	#S......F..........#
+//	####################
+//
+// Header keys are optional. Map characters: '#' wall, 'S' spawn, 'F' food,
+// '.' or space empty. Without "size" the field is the size of the map,
+// with it the map is padded with empty cells. Spawn defaults to the center.
+// Food from "food" lines follows food marked with 'F' (row by row).
+
+// LoadLevel reads level file
+func LoadLevel(filename string) (*Level, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("open level file: %w", err)
+	}
+	defer f.Close()
+
+	lvl, err := ParseLevel(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse level %s: %w", filename, err)
+	}
+	return lvl, nil
+}
+
+// ParseLevel reads level in text format
+func ParseLevel(r io.Reader) (*Level, error) {
+	lvl := &Level{Direction: Right}
+	var rows []string
+	var extraFood []Point
+	inMap := false
+	hasSpawn := false
+
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+
+		if inMap {
+			rows = append(rows, line)
+			continue
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected \"key: value\"", lineNum)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "name":
+			lvl.Name = value
+		case "wrap":
+			wrap, err := parseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			lvl.WrapAround = wrap
+		case "dir":
+			dir, err := parseDirection(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			lvl.Direction = dir
+		case "size":
+			w, h, ok := strings.Cut(strings.ToLower(value), "x")
+			width, errW := strconv.Atoi(strings.TrimSpace(w))
+			height, errH := strconv.Atoi(strings.TrimSpace(h))
+			if !ok || errW != nil || errH != nil {
+				return nil, fmt.Errorf("line %d: invalid size %q, want WxH", lineNum, value)
+			}
+			lvl.Width, lvl.Height = width, height
+		case "food":
+			p, err := parsePoint(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			extraFood = append(extraFood, p)
+		case "map":
+			inMap = true
+		default:
+			return nil, fmt.Errorf("line %d: unknown key %q", lineNum, key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	// Пустые строки в конце карты не считаются рядами
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	mapWidth := 0
+	for _, row := range rows {
+		mapWidth = max(mapWidth, len(row))
+	}
+	if lvl.Width == 0 && lvl.Height == 0 {
+		lvl.Width, lvl.Height = mapWidth, len(rows)
+	}
+	if mapWidth > lvl.Width || len(rows) > lvl.Height {
+		return nil, fmt.Errorf("map %dx%d does not fit level size %dx%d", mapWidth, len(rows), lvl.Width, lvl.Height)
+	}
+
+	for y, row := range rows {
+		for x, ch := range row {
+			p := Point{X: x, Y: y}
+			switch ch {
+			case '#':
+				lvl.Walls = append(lvl.Walls, p)
+			case 'S':
+				if hasSpawn {
+					return nil, fmt.Errorf("map row %d: more than one spawn", y+1)
+				}
+				lvl.Spawn, hasSpawn = p, true
+			case 'F':
+				lvl.Food = append(lvl.Food, p)
+			case '.', ' ':
+			default:
+				return nil, fmt.Errorf("map row %d: unknown character %q", y+1, ch)
+			}
+		}
+	}
+	lvl.Food = append(lvl.Food, extraFood...)
+
+	if !hasSpawn {
+		lvl.Spawn = Point{X: lvl.Width / 2, Y: lvl.Height / 2}
+	}
+	if err := lvl.Validate(); err != nil {
+		return nil, err
+	}
+
+	return lvl, nil
+}
+
+// Validate checks that spawn and food are inside the field and not in walls
+func (l *Level) Validate() error {
+	if l.Width < 3 || l.Height < 3 {
+		return fmt.Errorf("level size %dx%d is too small", l.Width, l.Height)
+	}
+
+	inside := func(p Point) bool {
+		return p.X >= 0 && p.X < l.Width && p.Y >= 0 && p.Y < l.Height
+	}
+	walls := make(map[Point]bool, len(l.Walls))
+	for _, p := range l.Walls {
+		if !inside(p) {
+			return fmt.Errorf("wall %d,%d is outside the field", p.X, p.Y)
+		}
+		walls[p] = true
+	}
+
+	if !inside(l.Spawn) || walls[l.Spawn] {
+		return fmt.Errorf("spawn %d,%d is outside the field or inside a wall", l.Spawn.X, l.Spawn.Y)
+	}
+	for _, p := range l.Food {
+		if !inside(p) || walls[p] {
+			return fmt.Errorf("food %d,%d is outside the field or inside a wall", p.X, p.Y)
+		}
+	}
+	return nil
+}
+
+// Write writes level in text format readable by ParseLevel
+func (l *Level) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if l.Name != "" {
+		fmt.Fprintf(bw, "name: %s\n", l.Name)
+	}
+	fmt.Fprintf(bw, "wrap: %t\n", l.WrapAround)
+	fmt.Fprintf(bw, "dir: %s\n", directionNames[l.Direction])
+
+	grid := make([][]byte, l.Height)
+	for y := range grid {
+		grid[y] = []byte(strings.Repeat(".", l.Width))
+	}
+	for _, p := range l.Walls {
+		grid[p.Y][p.X] = '#'
+	}
+
+	// Еда в порядке обхода карты пишется буквами 'F', иначе строками food
+	inMapOrder := true
+	seen := make(map[Point]bool, len(l.Food))
+	for i, p := range l.Food {
+		if seen[p] || (i > 0 && (p.Y < l.Food[i-1].Y || p.Y == l.Food[i-1].Y && p.X < l.Food[i-1].X)) {
+			inMapOrder = false
+			break
+		}
+		seen[p] = true
+	}
+	for _, p := range l.Food {
+		if inMapOrder {
+			grid[p.Y][p.X] = 'F'
+		} else {
+			fmt.Fprintf(bw, "food: %d,%d\n", p.X, p.Y)
+		}
+	}
+	grid[l.Spawn.Y][l.Spawn.X] = 'S'
+
+	fmt.Fprintln(bw, "map:")
+	for _, row := range grid {
+		bw.Write(row)
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// Save writes level file
+func (l *Level) Save(filename string) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+
+	var buf strings.Builder
+	if err := l.Write(&buf); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, []byte(buf.String()), 0644)
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q", value)
+}
+
+var directionNames = [...]string{Up: "up", Right: "right", Down: "down", Left: "left"}
+
+func parseDirection(value string) (Direction, error) {
+	for dir, name := range directionNames {
+		if strings.EqualFold(value, name) {
+			return Direction(dir), nil
+		}
+	}
+	return Right, fmt.Errorf("invalid direction %q", value)
+}
+
+func parsePoint(value string) (Point, error) {
+	xs, ys, ok := strings.Cut(value, ",")
+	x, errX := strconv.Atoi(strings.TrimSpace(xs))
+	y, errY := strconv.Atoi(strings.TrimSpace(ys))
+	if !ok || errX != nil || errY != nil {
+		return Point{}, fmt.Errorf("invalid point %q, want x,y", value)
+	}
+	return Point{X: x, Y: y}, nil
+}
@@ -0,0 +1,243 @@
+package snake
+
+import (
+	"container/heap"
+	"math"
+)
+
+// blocked is vacate time for cells that never become free
+const blocked = math.MaxInt
+
+// vacateTimes returns for every cell the number of moves after which it is
+// free. Body segment i leaves its cell after len(body)-i moves (tail first),
+// obstacles never do. Growth from eating on the way is not modelled.
+func (s *Snake) vacateTimes() []int {
+	times := make([]int, s.width*s.height)
+	for _, obs := range s.obstacles {
+		if s.inBounds(obs) {
+			times[s.cellIndex(obs)] = blocked
+		}
+	}
+	for i, segment := range s.body {
+		if s.inBounds(segment) {
+			times[s.cellIndex(segment)] = len(s.body) - i
+		}
+	}
+	return times
+}
+
+// inBounds checks if position lies inside the field
+func (s *Snake) inBounds(pos Point) bool {
+	return pos.X >= 0 && pos.X < s.width && pos.Y >= 0 && pos.Y < s.height
+}
+
+// cellIndex converts in-bounds position to flat index
+func (s *Snake) cellIndex(pos Point) int {
+	return pos.Y*s.width + pos.X
+}
+
+// neighbor returns adjacent cell in direction, ok is false when it is off
+// the field without wrap-around
+func (s *Snake) neighbor(pos Point, dir Direction) (Point, bool) {
+	next := s.normalizePos(pos.Add(dir.ToVector()))
+	return next, s.inBounds(next)
+}
+
+// distance returns Manhattan distance, taking wrap-around into account
+func (s *Snake) distance(a, b Point) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+	if s.wrapAround {
+		dx = min(dx, s.width-dx)
+		dy = min(dy, s.height-dy)
+	}
+	return dx + dy
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// firstMoves returns directions allowed for the first step: reversing
+// is a no-op in Step, so it never leads to the opposite cell
+func (s *Snake) firstMoves() []Direction {
+	dirs := make([]Direction, 0, 4)
+	for _, dir := range []Direction{Up, Right, Down, Left} {
+		if !s.direction.IsOpposite(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// FindPath returns shortest path from head to target with A*, excluding
+// the head and including the target, or nil if target is unreachable.
+// Cells occupied by the body count as free once the tail has left them.
+func (s *Snake) FindPath(target Point) []Point {
+	target = s.normalizePos(target)
+	if !s.inBounds(target) {
+		return nil
+	}
+
+	times := s.vacateTimes()
+	head := s.body[0]
+	cells := s.width * s.height
+
+	cost := make([]int, cells)
+	parent := make([]int, cells)
+	for i := range cost {
+		cost[i] = -1
+	}
+
+	open := &pathQueue{}
+	startIdx := s.cellIndex(head)
+	cost[startIdx] = 0
+	parent[startIdx] = -1
+	heap.Push(open, pathNode{index: startIdx, priority: s.distance(head, target)})
+
+	for open.Len() > 0 {
+		cur := heap.Pop(open).(pathNode)
+		pos := Point{X: cur.index % s.width, Y: cur.index / s.width}
+		if pos.Equal(target) {
+			return s.tracePath(parent, cur.index)
+		}
+
+		dirs := []Direction{Up, Right, Down, Left}
+		if cur.index == startIdx {
+			dirs = s.firstMoves()
+		}
+
+		for _, dir := range dirs {
+			next, ok := s.neighbor(pos, dir)
+			if !ok {
+				continue
+			}
+
+			idx := s.cellIndex(next)
+			g := cost[cur.index] + 1
+			if times[idx] > g || (cost[idx] >= 0 && cost[idx] <= g) {
+				continue
+			}
+
+			cost[idx] = g
+			parent[idx] = cur.index
+			heap.Push(open, pathNode{index: idx, priority: g + s.distance(next, target)})
+		}
+	}
+
+	return nil
+}
+
+// tracePath rebuilds path from parent links
+func (s *Snake) tracePath(parent []int, end int) []Point {
+	var path []Point
+	for idx := end; parent[idx] >= 0; idx = parent[idx] {
+		path = append(path, Point{X: idx % s.width, Y: idx / s.width})
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// ShortestPathLength returns number of moves from head to target found
+// with breadth-first search, or -1 if target is unreachable
+func (s *Snake) ShortestPathLength(target Point) int {
+	dist := s.BFSDistances()
+	target = s.normalizePos(target)
+	if !s.inBounds(target) {
+		return -1
+	}
+	return dist[s.cellIndex(target)]
+}
+
+// BFSDistances returns moves needed to reach every cell from the head
+// (-1 for unreachable), indexed by y*Width()+x
+func (s *Snake) BFSDistances() []int {
+	times := s.vacateTimes()
+	dist := make([]int, s.width*s.height)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	head := s.body[0]
+	startIdx := s.cellIndex(head)
+	dist[startIdx] = 0
+	queue := []Point{head}
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+		d := dist[s.cellIndex(pos)]
+
+		dirs := []Direction{Up, Right, Down, Left}
+		if d == 0 {
+			dirs = s.firstMoves()
+		}
+
+		for _, dir := range dirs {
+			next, ok := s.neighbor(pos, dir)
+			if !ok {
+				continue
+			}
+			idx := s.cellIndex(next)
+			if dist[idx] >= 0 || times[idx] > d+1 {
+				continue
+			}
+			dist[idx] = d + 1
+			queue = append(queue, next)
+		}
+	}
+
+	return dist
+}
+
+// DirectionTo returns direction of a step between adjacent cells
+func (s *Snake) DirectionTo(from, to Point) (Direction, bool) {
+	for _, dir := range []Direction{Up, Right, Down, Left} {
+		if next, ok := s.neighbor(from, dir); ok && next.Equal(to) {
+			return dir, true
+		}
+	}
+	return s.direction, false
+}
+
+// SafeActions returns actions (in current action mode) that do not end
+// the game on the next move
+func (s *Snake) SafeActions() []int {
+	times := s.vacateTimes()
+	safe := make([]int, 0, 4)
+	for _, dir := range s.firstMoves() {
+		next, ok := s.neighbor(s.body[0], dir)
+		if !ok {
+			continue
+		}
+		if times[s.cellIndex(next)] <= 1 {
+			safe = append(safe, s.ActionFor(dir))
+		}
+	}
+	return safe
+}
+
+// pathNode is an A* open set entry
+type pathNode struct {
+	index    int
+	priority int
+}
+
+// pathQueue is a min-heap of A* nodes ordered by priority
+type pathQueue []pathNode
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathNode)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	*q = old[:n-1]
+	return item
+}
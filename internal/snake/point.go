@@ -4,7 +4,8 @@ import "math"
 
 // Point represents coordinates on grid
 type Point struct {
-	X, Y int
+	X int `json:"x"`
+	Y int `json:"y"`
 }
 
 // Equal checks if two points are equal
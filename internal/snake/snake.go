@@ -7,32 +7,143 @@ import (
 
 // Snake represents game field and snake
 type Snake struct {
-	width        int
-	height       int
-	body         []Point
-	food         Point
-	obstacles    []Point
-	direction    Direction
-	score        int
-	steps        int
-	maxSteps     int
-	wrapAround   bool
-	dynamicSize  bool
-	initialSize  int
-	lastPositions []Point // ✅ НОВОЕ: для отслеживания цикличности
+	width            int
+	height           int
+	body             []Point
+	food             Point
+	obstacles        []Point
+	direction        Direction
+	score            int
+	steps            int
+	maxSteps         int
+	wrapAround       bool
+	dynamicSize      bool
+	initialSize      int
+	lastPositions    []Point // ✅ НОВОЕ: для отслеживания цикличности
+	seed             uint64
+	src              *rand.PCG
+	rng              *rand.Rand
+	recorder         *Recorder
+	features         Features
+	actionMode       ActionMode
+	level            *Level
+	foodSequence     []Point // Fixed food positions of a level, used in order
+	foodIndex        int
+	obstacleInterval int // Add random obstacle every N points, 0 disables
+	obstaclesMin     int // Initial scattered obstacles
+	obstaclesMax     int
+	layout           Layout
+}
+
+// Features selects optional observation features appended to GetState
+type Features uint8
+
+const (
+	FeaturePath  Features = 1 << iota // BFS path length to food, path exists
+	FeatureFlood                      // Flood-fill area and tail reachability per move
+)
+
+// DefaultFeatures returns observation features enabled in central config
+func DefaultFeatures() Features {
+	var f Features
+	if config.PathFeaturesEnabled {
+		f |= FeaturePath
+	}
+	if config.FloodFeaturesEnabled {
+		f |= FeatureFlood
+	}
+	return f
+}
+
+// Options holds field configuration for a new snake
+type Options struct {
+	Width       int
+	Height      int
+	WrapAround  bool
+	DynamicSize bool
+	Seed        uint64 // 0 picks a random seed
+	Features    Features
+	ActionMode  ActionMode
+	Level       *Level // Fixed layout; overrides size and wrap-around
+	Layout      Layout // Obstacle generator of random fields
+
+	ObstaclesMin     int // Initial obstacles of the scatter layout
+	ObstaclesMax     int
+	ObstacleInterval int // Add an obstacle every N points, 0 disables
+
+	FoodSequence []Point // Fixed food positions used in order, e.g. from NewFoodSequence
+}
+
+// DefaultOptions returns field options from central config
+func DefaultOptions() Options {
+	return Options{
+		Width:       config.InitialFieldWidth,
+		Height:      config.InitialFieldHeight,
+		WrapAround:  config.WrapAroundEnabled,
+		DynamicSize: config.DynamicSizeEnabled,
+		Features:    DefaultFeatures(),
+		ActionMode:  DefaultActionMode(),
+		Layout:      DefaultLayout(),
+
+		ObstaclesMin:     config.InitialObstaclesMin,
+		ObstaclesMax:     config.InitialObstaclesMax,
+		ObstacleInterval: config.ObstacleAddInterval,
+	}
 }
 
 // NewSnake creates new snake instance using config
 func NewSnake(width, height int, wrapAround, dynamicSize bool) *Snake {
-	s := &Snake{
-		width:         width,
-		height:        height,
-		wrapAround:    wrapAround,
-		dynamicSize:   dynamicSize,
-		initialSize:   width,
-		maxSteps:      width * height * 3,
-		lastPositions: make([]Point, 0, 10),
+	opts := DefaultOptions()
+	opts.Width = width
+	opts.Height = height
+	opts.WrapAround = wrapAround
+	opts.DynamicSize = dynamicSize
+	return NewSnakeWithOptions(opts)
+}
+
+// NewSnakeWithOptions creates new snake instance with explicit options.
+// All randomness (food, obstacles) is drawn from a generator seeded with
+// opts.Seed, so two snakes with the same seed and actions play identically.
+func NewSnakeWithOptions(opts Options) *Snake {
+	seed := opts.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
 	}
+
+	s := &Snake{
+		width:            opts.Width,
+		height:           opts.Height,
+		wrapAround:       opts.WrapAround,
+		dynamicSize:      opts.DynamicSize,
+		initialSize:      opts.Width,
+		maxSteps:         opts.Width * opts.Height * 3,
+		lastPositions:    make([]Point, 0, 10),
+		seed:             seed,
+		src:              rand.NewPCG(seed, seed),
+		features:         opts.Features,
+		actionMode:       opts.ActionMode,
+		obstacleInterval: opts.ObstacleInterval,
+		obstaclesMin:     opts.ObstaclesMin,
+		obstaclesMax:     max(opts.ObstaclesMax, opts.ObstaclesMin),
+		layout:           opts.Layout,
+		foodSequence:     opts.FoodSequence,
+	}
+
+	// Уровень задает поле целиком: без расширения и случайных препятствий
+	if lvl := opts.Level; lvl != nil {
+		s.level = lvl
+		s.width, s.height = lvl.Width, lvl.Height
+		s.wrapAround = lvl.WrapAround
+		s.dynamicSize = false
+		s.initialSize = lvl.Width
+		s.maxSteps = lvl.Width * lvl.Height * 3
+		if len(lvl.Food) > 0 {
+			s.foodSequence = lvl.Food
+		}
+		s.obstacleInterval = 0
+	}
+
+	s.rng = rand.New(s.src)
 	s.Reset()
 	return s
 }
@@ -46,9 +157,29 @@ func (s *Snake) Reset() {
 	s.steps = 0
 	s.obstacles = nil
 	s.lastPositions = make([]Point, 0, 10)
+	s.foodIndex = 0
+
+	if s.level != nil {
+		s.body = []Point{s.level.Spawn}
+		s.direction = s.level.Direction
+		s.obstacles = append([]Point(nil), s.level.Walls...)
+		s.spawnFood()
+		return
+	}
+
+	layout := s.layout
+	if layout == LayoutMixed {
+		layout = Layout(s.rng.IntN(int(LayoutMixed)))
+	}
+	if layout != LayoutScatter {
+		s.generateObstacles(layout)
+		s.spawnFood()
+		return
+	}
+
 	s.spawnFood()
 
-	initialObstacles := config.InitialObstaclesMin + rand.IntN(config.InitialObstaclesMax-config.InitialObstaclesMin+1)
+	initialObstacles := s.obstaclesMin + s.rng.IntN(s.obstaclesMax-s.obstaclesMin+1)
 	s.addObstacles(initialObstacles)
 }
 
@@ -62,6 +193,11 @@ func (s *Snake) Score() int                  { return s.score }
 func (s *Snake) Steps() int                  { return s.steps }
 func (s *Snake) CurrentDirection() Direction { return s.direction }
 func (s *Snake) Length() int                 { return len(s.body) }
+func (s *Snake) WrapAround() bool            { return s.wrapAround }
+func (s *Snake) Seed() uint64                { return s.seed }
+func (s *Snake) Features() Features          { return s.features }
+func (s *Snake) Level() *Level               { return s.level }
+func (s *Snake) Layout() Layout              { return s.layout }
 
 // GetOccupancy returns field occupancy percentage
 func (s *Snake) GetOccupancy() float64 {
@@ -72,14 +208,56 @@ func (s *Snake) GetOccupancy() float64 {
 	return float64(len(s.body)) / float64(totalCells)
 }
 
-// spawnFood generates food at random free position
+// spawnFood places food at next free fixed position of the level,
+// or at random free position
 func (s *Snake) spawnFood() {
-	for attempt := 0; attempt < 1000; attempt++ {
-		s.food = Point{X: rand.IntN(s.width), Y: rand.IntN(s.height)}
-		if s.isCellFree(s.food) {
-			return
+	if !s.spawnFixedFood() {
+		for attempt := 0; attempt < 1000; attempt++ {
+			s.food = Point{X: s.rng.IntN(s.width), Y: s.rng.IntN(s.height)}
+			if s.isCellFree(s.food) {
+				break
+			}
+		}
+	}
+
+	if s.recorder != nil {
+		s.recorder.recordFood(s.steps, s.food)
+	}
+}
+
+// spawnFixedFood places food number foodIndex of the sequence. When that
+// cell is taken it falls back to earlier positions, so upcoming food stays
+// where it would be without the body in the way.
+func (s *Snake) spawnFixedFood() bool {
+	n := len(s.foodSequence)
+	if n == 0 {
+		return false
+	}
+	start := s.foodIndex
+	s.foodIndex++
+	for i := 0; i < n; i++ {
+		pos := s.foodSequence[((start-i)%n+n)%n]
+		if s.isCellFree(pos) {
+			s.food = pos
+			return true
 		}
 	}
+	return false
+}
+
+// NewFoodSequence returns every cell of a width x height field in an
+// order shuffled by seed. Snakes sharing it get food at the same places
+// no matter how they move; cells taken by the body are skipped.
+func NewFoodSequence(seed uint64, width, height int) []Point {
+	seq := make([]Point, 0, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			seq = append(seq, Point{X: x, Y: y})
+		}
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	rng.Shuffle(len(seq), func(i, j int) { seq[i], seq[j] = seq[j], seq[i] })
+	return seq
 }
 
 // addObstacles adds random obstacles
@@ -91,7 +269,7 @@ func (s *Snake) addObstacles(count int) {
 		placed := false
 
 		for attempt := 0; attempt < maxAttempts; attempt++ {
-			obs := Point{X: rand.IntN(s.width), Y: rand.IntN(s.height)}
+			obs := Point{X: s.rng.IntN(s.width), Y: s.rng.IntN(s.height)}
 
 			tooCloseToSnake := false
 			for _, segment := range s.body {
@@ -114,6 +292,9 @@ func (s *Snake) addObstacles(count int) {
 			}
 
 			s.obstacles = append(s.obstacles, obs)
+			if s.recorder != nil {
+				s.recorder.recordObstacle(s.steps, obs)
+			}
 			placed = true
 			break
 		}
@@ -184,8 +365,22 @@ func (s *Snake) normalizePos(pos Point) Point {
 	return pos
 }
 
-// ✅ КРИТИЧЕСКИ ИСПРАВЛЕНО: Step с правильной проверкой wrap-around
+// Step advances the game by one move and returns reward and done flag
 func (s *Snake) Step(action int) (float64, bool) {
+	if s.recorder != nil {
+		s.recorder.recordAction(s.steps+1, action)
+	}
+
+	reward, done := s.step(int(s.ActionDirection(action)))
+
+	if done && s.recorder != nil {
+		s.recorder.recordEnd(s)
+	}
+	return reward, done
+}
+
+// ✅ КРИТИЧЕСКИ ИСПРАВЛЕНО: step с правильной проверкой wrap-around
+func (s *Snake) step(action int) (float64, bool) {
 	s.steps++
 
 	newDir := Direction(action)
@@ -206,7 +401,7 @@ func (s *Snake) Step(action int) (float64, bool) {
 	reward := config.RewardStep
 
 	// Проверка границ (только если нет wrap-around)
-	if !s.wrapAround && (originalNewHead.X < 0 || originalNewHead.X >= s.width || 
+	if !s.wrapAround && (originalNewHead.X < 0 || originalNewHead.X >= s.width ||
 		originalNewHead.Y < 0 || originalNewHead.Y >= s.height) {
 		return config.RewardDeath, true
 	}
@@ -216,12 +411,12 @@ func (s *Snake) Step(action int) (float64, bool) {
 	for i, segment := range s.body {
 		// Нормализуем позицию сегмента тела для корректного сравнения
 		normalizedSegment := s.normalizePos(segment)
-		
+
 		// Пропускаем хвост если не едим еду
 		if !willEatFood && i == len(s.body)-1 {
 			continue
 		}
-		
+
 		if newHead.Equal(normalizedSegment) {
 			return config.RewardDeath, true
 		}
@@ -274,14 +469,17 @@ func (s *Snake) Step(action int) (float64, bool) {
 		// Очищаем историю позиций при поедании еды (новая игра)
 		s.lastPositions = make([]Point, 0, 10)
 
-		if s.dynamicSize && s.GetOccupancy() >= config.ExpansionThreshold && 
+		if s.dynamicSize && s.GetOccupancy() >= config.ExpansionThreshold &&
 			s.width < s.initialSize*config.MaxFieldExpansion {
 			s.width += config.ExpansionIncrement
 			s.height += config.ExpansionIncrement
 			s.maxSteps = s.width * s.height * 3
+			if s.recorder != nil {
+				s.recorder.recordExpand(s.steps, s.width, s.height)
+			}
 		}
 
-		if s.score%config.ObstacleAddInterval == 0 {
+		if s.obstacleInterval > 0 && s.score%s.obstacleInterval == 0 {
 			s.addObstacles(1)
 		}
 	} else {
@@ -318,7 +516,6 @@ func (s *Snake) Step(action int) (float64, bool) {
 	return reward, false
 }
 
-
 // ✅ НОВОЕ: подсчет свободного пространства вокруг позиции
 func (s *Snake) countFreeSpace(pos Point) int {
 	neighbors := pos.GetNeighbors()
@@ -326,16 +523,16 @@ func (s *Snake) countFreeSpace(pos Point) int {
 
 	for _, neighbor := range neighbors {
 		neighbor = s.normalizePos(neighbor)
-		
+
 		if !s.wrapAround {
-			if neighbor.X < 0 || neighbor.X >= s.width || 
+			if neighbor.X < 0 || neighbor.X >= s.width ||
 				neighbor.Y < 0 || neighbor.Y >= s.height {
 				continue
 			}
 		}
 
 		isFree := true
-		
+
 		// Проверка тела
 		for _, segment := range s.body {
 			if s.normalizePos(neighbor).Equal(s.normalizePos(segment)) {
@@ -450,7 +647,7 @@ func (s *Snake) GetState() []float64 {
 	freeSpaceDown := float64(s.countFreeSpaceInDirection(head, Down)) / 4.0
 	freeSpaceLeft := float64(s.countFreeSpaceInDirection(head, Left)) / 4.0
 
-	return []float64{
+	state := []float64{
 		dangerStraight, dangerRight, dangerLeft,
 		foodUp, foodRight, foodDown, foodLeft,
 		dirUp, dirRight, dirDown, dirLeft,
@@ -460,6 +657,21 @@ func (s *Snake) GetState() []float64 {
 		lengthNorm, manhattanDist,
 		freeSpaceUp, freeSpaceRight, freeSpaceDown, freeSpaceLeft, // ✅ НОВОЕ
 	}
+
+	// Опциональные признаки: длина пути до еды (BFS) и его наличие
+	if s.features&FeaturePath != 0 {
+		pathLen := s.ShortestPathLength(s.food)
+		if pathLen >= 0 {
+			state = append(state, float64(pathLen)/float64(s.width*s.height), 1)
+		} else {
+			state = append(state, 1, 0)
+		}
+	}
+	if s.features&FeatureFlood != 0 {
+		state = append(state, s.floodFeatureValues()...)
+	}
+
+	return state
 }
 
 // ✅ НОВОЕ: подсчет свободного пространства в направлении
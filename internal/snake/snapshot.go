@@ -0,0 +1,104 @@
+package snake
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// Snapshot is a deep copy of the full game state, including the random
+// generator, so restoring it reproduces exactly the same future
+type Snapshot struct {
+	width            int
+	height           int
+	body             []Point
+	food             Point
+	obstacles        []Point
+	direction        Direction
+	score            int
+	steps            int
+	maxSteps         int
+	wrapAround       bool
+	dynamicSize      bool
+	initialSize      int
+	lastPositions    []Point
+	seed             uint64
+	rng              rand.PCG
+	features         Features
+	actionMode       ActionMode
+	level            *Level
+	foodSequence     []Point
+	foodIndex        int
+	obstacleInterval int
+	obstaclesMin     int
+	obstaclesMax     int
+	layout           Layout
+}
+
+// Snapshot captures current game state
+func (s *Snake) Snapshot() Snapshot {
+	return Snapshot{
+		width:            s.width,
+		height:           s.height,
+		body:             slices.Clone(s.body),
+		food:             s.food,
+		obstacles:        slices.Clone(s.obstacles),
+		direction:        s.direction,
+		score:            s.score,
+		steps:            s.steps,
+		maxSteps:         s.maxSteps,
+		wrapAround:       s.wrapAround,
+		dynamicSize:      s.dynamicSize,
+		initialSize:      s.initialSize,
+		lastPositions:    slices.Clone(s.lastPositions),
+		seed:             s.seed,
+		rng:              *s.src,
+		features:         s.features,
+		actionMode:       s.actionMode,
+		level:            s.level,
+		foodSequence:     s.foodSequence,
+		foodIndex:        s.foodIndex,
+		obstacleInterval: s.obstacleInterval,
+		obstaclesMin:     s.obstaclesMin,
+		obstaclesMax:     s.obstaclesMax,
+		layout:           s.layout,
+	}
+}
+
+// Restore returns snake to a previously captured state.
+// An attached recorder is kept but will not match the restored history.
+func (s *Snake) Restore(snap Snapshot) {
+	s.width = snap.width
+	s.height = snap.height
+	s.body = slices.Clone(snap.body)
+	s.food = snap.food
+	s.obstacles = slices.Clone(snap.obstacles)
+	s.direction = snap.direction
+	s.score = snap.score
+	s.steps = snap.steps
+	s.maxSteps = snap.maxSteps
+	s.wrapAround = snap.wrapAround
+	s.dynamicSize = snap.dynamicSize
+	s.initialSize = snap.initialSize
+	s.lastPositions = slices.Clone(snap.lastPositions)
+	s.seed = snap.seed
+	*s.src = snap.rng
+	s.features = snap.features
+	s.actionMode = snap.actionMode
+	s.level = snap.level
+	s.foodSequence = snap.foodSequence
+	s.foodIndex = snap.foodIndex
+	s.obstacleInterval = snap.obstacleInterval
+	s.obstaclesMin = snap.obstaclesMin
+	s.obstaclesMax = snap.obstaclesMax
+	s.layout = snap.layout
+}
+
+// Clone returns independent deep copy of the snake. The copy is not
+// recorded even if the original is, so it is safe to use for lookahead.
+func (s *Snake) Clone() *Snake {
+	src := *s.src
+	c := &Snake{src: &src}
+	c.rng = rand.New(c.src)
+	c.Restore(s.Snapshot())
+	return c
+}
@@ -0,0 +1,54 @@
+// Package levels holds built-in hand-designed level files
+package levels
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"snakes-ml/config"
+	"snakes-ml/internal/snake"
+)
+
+//go:embed *.lvl
+var files embed.FS
+
+// Names returns names of built-in levels in alphabetical order
+func Names() []string {
+	entries, _ := fs.ReadDir(files, ".")
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		names = append(names, strings.TrimSuffix(e.Name(), config.LevelExt))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load returns built-in level by name
+func Load(name string) (*snake.Level, error) {
+	data, err := files.ReadFile(path.Clean(name) + config.LevelExt)
+	if err != nil {
+		return nil, fmt.Errorf("unknown level %q", name)
+	}
+
+	lvl, err := snake.ParseLevel(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse level %s: %w", name, err)
+	}
+	if lvl.Name == "" {
+		lvl.Name = name
+	}
+	return lvl, nil
+}
+
+// Find loads built-in level by name, or a level file when name is a path
+func Find(name string) (*snake.Level, error) {
+	if strings.HasSuffix(name, config.LevelExt) {
+		return snake.LoadLevel(name)
+	}
+	return Load(name)
+}